@@ -1,16 +1,33 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"log/slog"
+	"net/http"
 	"os"
-
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"review-assignment/internal/api/audit_handler"
+	"review-assignment/internal/api/health_handler"
+	"review-assignment/internal/api/integration_handler"
 	"review-assignment/internal/api/pr_handler"
 	"review-assignment/internal/api/team_handler"
 	"review-assignment/internal/api/user_handler"
+	"review-assignment/internal/api/webhook_handler"
 	"review-assignment/internal/config"
-	"review-assignment/internal/database"
+	"review-assignment/internal/lib/http/errhandler"
+	"review-assignment/internal/lib/http/timeout"
 	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/migrations"
 	"review-assignment/internal/storage"
+	"review-assignment/internal/worker/sla"
+	"review-assignment/internal/worker/webhook"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,49 +38,205 @@ func main() {
 	}))
 
 	cfg := config.Load()
-	log.Info("starting application")
 
-	db, err := database.New(cfg.GetDBConnString())
-	if err != nil {
-		log.Error("failed to connect to database", sl.Err(err))
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		code := runMigrate(ctx, log, cfg, os.Args[2:])
+		stop()
+		os.Exit(code)
 	}
-	defer db.Close()
-	log.Info("database connected successfully")
 
-	storage := storage.New(db)
+	log.Info("starting application")
 
-	if err := storage.Init(); err != nil {
-		log.Error("failed to init database tables", sl.Err(err))
+	var (
+		db       *sql.DB
+		migrator *migrations.Migrator
+		repo     storage.Repository
+	)
+	switch cfg.StorageDriver {
+	case config.StorageDriverPostgres, config.StorageDriverSQLite:
+		var err error
+		db, migrator, err = openDatabase(cfg, log.With(slog.String("component", "migrations")))
+		if err != nil {
+			log.Error("failed to open database", sl.Err(err))
+			os.Exit(1)
+		}
+		defer db.Close()
+		log.Info("database connected successfully", slog.String("driver", cfg.StorageDriver))
+
+		if cfg.MigrateOnStart {
+			if _, err := migrator.Up(ctx); err != nil {
+				log.Error("failed to migrate database", sl.Err(err))
+				os.Exit(1)
+			}
+		}
+
+		if cfg.StorageDriver == config.StorageDriverSQLite {
+			repo = storage.NewSQLite(db, cfg.BusinessHours)
+			metrics.RegisterDB(db, cfg.SQLitePath)
+		} else {
+			repo = storage.New(db, cfg.BusinessHours)
+			metrics.RegisterDB(db, cfg.DBName)
+		}
+
+	case config.StorageDriverMemory:
+		log.Warn("using in-memory storage: data will be lost on restart")
+		repo = storage.NewMemory(cfg.BusinessHours)
+
+	default:
+		log.Error("unknown storage driver", slog.String("driver", cfg.StorageDriver))
 		os.Exit(1)
 	}
-	teamHandler := team_handler.NewTeamHandler(storage, log.With(slog.String("handler", "team")))
-	userHandler := user_handler.NewUserHandler(storage, log.With(slog.String("handler", "user")))
-	prHandler := pr_handler.NewPRHandler(storage, log.With(slog.String("handler", "pr")))
-
-	router := setupRouter(teamHandler, userHandler, prHandler)
+	metrics.RegisterOpenReviews(repo.CountOpenReviewsByTeam, cfg.RequestTimeout)
+
+	teamHandler := team_handler.NewTeamHandler(repo, log.With(slog.String("handler", "team")))
+	userHandler := user_handler.NewUserHandler(repo, log.With(slog.String("handler", "user")))
+	prHandler := pr_handler.NewPRHandler(repo, log.With(slog.String("handler", "pr")))
+	auditHandler := audit_handler.NewAuditHandler(repo, log.With(slog.String("handler", "audit")))
+	webhookHandler := webhook_handler.NewWebhookHandler(repo, log.With(slog.String("handler", "webhook")))
+	healthHandler := health_handler.NewHealthHandler(db, migrator, log.With(slog.String("handler", "health")), cfg.ReadinessTimeout)
+	integrationHandler := integration_handler.NewIntegrationHandler(repo, log.With(slog.String("handler", "integration")), integration_handler.Config{
+		GitHubSecret:  cfg.GitHubWebhookSecret,
+		GitLabToken:   cfg.GitLabWebhookToken,
+		DeliveryLease: cfg.RequestTimeout,
+	})
+
+	// воркеры останавливаются отдельно, после того как HTTP-сервер обработает текущие запросы
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+
+	slaWorker := sla.New(repo, log.With(slog.String("worker", "sla")), cfg.SLACheckInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		slaWorker.Run(workerCtx)
+	}()
+
+	webhookWorker := webhook.New(repo, nil, log.With(slog.String("worker", "webhook")), webhook.Config{
+		Interval:    cfg.WebhookDispatchInterval,
+		BatchSize:   cfg.WebhookBatchSize,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		BaseBackoff: cfg.WebhookBaseBackoff,
+		MaxBackoff:  cfg.WebhookMaxBackoff,
+		Timeout:     cfg.WebhookTimeout,
+	})
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		webhookWorker.Run(workerCtx)
+	}()
+
+	router := setupRouter(log, cfg, healthHandler, teamHandler, userHandler, prHandler, auditHandler, webhookHandler, integrationHandler)
+
+	server := &http.Server{
+		Addr:              ":" + cfg.ServerPort,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
 
-	log.Info("server starting", slog.String("port", cfg.ServerPort))
-	if err := router.Run(":" + cfg.ServerPort); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info("server starting", slog.String("port", cfg.ServerPort))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Info("shutdown signal received")
+	case err := <-serverErr:
 		log.Error("server failed", sl.Err(err))
 		os.Exit(1)
 	}
-}
 
-func setupRouter(teamHandler *team_handler.TeamHandler, userHandler *user_handler.UserHandler, prHandler *pr_handler.PRHandler) *gin.Engine {
-	router := gin.Default()
+	healthHandler.SetDraining()
 
-	router.GET("/health", prHandler.Health)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
 
-	router.POST("/team/add", teamHandler.CreateTeam)
-	router.GET("/team/get", teamHandler.GetTeam)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to drain in-flight requests", sl.Err(err))
+	}
 
-	router.POST("/users/setIsActive", userHandler.SetUserActive)
-	router.GET("/users/getReview", userHandler.GetUserReviews)
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Info("server stopped")
+	case <-shutdownCtx.Done():
+		log.Error("background workers did not stop before shutdown timeout")
+	}
+}
 
-	router.POST("/pullRequest/create", prHandler.CreatePR)
-	router.POST("/pullRequest/merge", prHandler.MergePR)
-	router.POST("/pullRequest/reassign", prHandler.ReassignReviewer)
+func setupRouter(
+	log *slog.Logger,
+	cfg *config.Config,
+	healthHandler *health_handler.HealthHandler,
+	teamHandler *team_handler.TeamHandler,
+	userHandler *user_handler.UserHandler,
+	prHandler *pr_handler.PRHandler,
+	auditHandler *audit_handler.AuditHandler,
+	webhookHandler *webhook_handler.WebhookHandler,
+	integrationHandler *integration_handler.IntegrationHandler,
+) *gin.Engine {
+	router := gin.Default()
+	router.Use(metrics.Middleware(), errhandler.Middleware(log))
+
+	router.GET("/health", healthHandler.Live)
+	router.GET("/livez", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/metrics", metrics.Handler())
+
+	api := router.Group("", timeout.Middleware(cfg.RequestTimeout))
+
+	api.POST("/team/add", teamHandler.CreateTeam)
+	api.GET("/team/get", teamHandler.GetTeam)
+	api.GET("/team/policy/get", teamHandler.GetPolicy)
+	api.POST("/team/policy/set", teamHandler.SetPolicy)
+	api.POST("/team/deactivateUsers", teamHandler.DeactivateUsers)
+	api.POST("/team/removeMember", teamHandler.RemoveMember)
+	api.POST("/team/moveMember", teamHandler.MoveMember)
+	api.POST("/team/delete", teamHandler.DeleteTeam)
+
+	api.POST("/users/setIsActive", userHandler.SetUserActive)
+	api.GET("/users/getReview", userHandler.GetUserReviews)
+	api.GET("/users/ooo/list", userHandler.ListOutOfOffice)
+	api.POST("/users/ooo/add", userHandler.AddOutOfOffice)
+	api.POST("/users/ooo/remove", userHandler.RemoveOutOfOffice)
+
+	api.GET("/pullRequest/list", prHandler.ListPRs)
+	api.GET("/pullRequest/get", prHandler.GetPR)
+	api.POST("/pullRequest/create", prHandler.CreatePR)
+	api.POST("/pullRequest/merge", prHandler.MergePR)
+	api.POST("/pullRequest/reassign", prHandler.ReassignReviewer)
+	api.POST("/pullRequest/close", prHandler.ClosePR)
+	api.POST("/pullRequest/ready", prHandler.MarkReady)
+	api.POST("/pullRequest/reopen", prHandler.ReopenPR)
+	api.POST("/pullRequest/review", prHandler.SubmitReview)
+	api.GET("/pullRequest/overdue", prHandler.ListOverdue)
+
+	// выгрузка аудита в NDJSON может идти дольше обычного запроса
+	router.GET("/audit/list", timeout.Middleware(cfg.ExportRequestTimeout), auditHandler.List)
+
+	api.POST("/webhooks/add", webhookHandler.Add)
+	api.GET("/webhooks/list", webhookHandler.List)
+	api.POST("/webhooks/remove", webhookHandler.Remove)
+	api.GET("/webhooks/deliveries", webhookHandler.ListDeliveries)
+	api.POST("/webhooks/redeliver", webhookHandler.Redeliver)
+
+	api.GET("/integrations/accounts/list", integrationHandler.ListAccounts)
+	api.POST("/integrations/accounts/set", integrationHandler.SetAccount)
+	api.POST("/integrations/accounts/remove", integrationHandler.RemoveAccount)
+	api.POST("/integrations/github/webhook", integrationHandler.GitHub)
+	api.POST("/integrations/gitlab/webhook", integrationHandler.GitLab)
 
 	return router
 }
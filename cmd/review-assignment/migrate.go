@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"review-assignment/internal/config"
+	"review-assignment/internal/database"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/migrations"
+)
+
+const migrateUsage = "usage: review-assignment migrate up | down [steps] | status"
+
+// runMigrate выполняет подкоманду migrate и возвращает код завершения процесса
+func runMigrate(ctx context.Context, log *slog.Logger, cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	// драйвер по умолчанию не должен молча направить миграции в Postgres
+	if os.Getenv("STORAGE_DRIVER") == "" {
+		fmt.Fprintln(os.Stderr, "STORAGE_DRIVER is not set: migrate needs postgres or sqlite")
+		return 2
+	}
+	if cfg.StorageDriver == config.StorageDriverMemory {
+		fmt.Fprintln(os.Stderr, "STORAGE_DRIVER=memory keeps no schema, there is nothing to migrate")
+		return 2
+	}
+
+	db, migrator, err := openDatabase(cfg, log)
+	if err != nil {
+		log.Error("failed to open database", sl.Err(err))
+		return 1
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Error("migration failed", sl.Err(err))
+			return 1
+		}
+		log.Info("migrations applied", slog.Int("count", applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Error("migration rollback failed", sl.Err(err))
+			return 1
+		}
+		log.Info("migrations reverted", slog.Int("count", reverted))
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Error("failed to read migration status", sl.Err(err))
+			return 1
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		for _, s := range status {
+			if err := encoder.Encode(s); err != nil {
+				return 1
+			}
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
+
+// openDatabase подключается к БД выбранного драйвера и создаёт мигратор под её диалект
+func openDatabase(cfg *config.Config, log *slog.Logger) (*sql.DB, *migrations.Migrator, error) {
+	var (
+		open        func(dsn string) (*sql.DB, error)
+		newMigrator func(db *sql.DB, log *slog.Logger) (*migrations.Migrator, error)
+		dsn         string
+	)
+	switch cfg.StorageDriver {
+	case config.StorageDriverPostgres:
+		open, newMigrator, dsn = database.New, migrations.New, cfg.GetDBConnString()
+	case config.StorageDriverSQLite:
+		open, newMigrator, dsn = database.NewSQLite, migrations.NewSQLite, cfg.SQLitePath
+	default:
+		return nil, nil, fmt.Errorf("storage driver %q has no database, use %s or %s",
+			cfg.StorageDriver, config.StorageDriverPostgres, config.StorageDriverSQLite)
+	}
+
+	db, err := open(dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	migrator, err := newMigrator(db, log)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, migrator, nil
+}
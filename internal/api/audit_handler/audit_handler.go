@@ -0,0 +1,116 @@
+package audit_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"review-assignment/internal/lib/http/response"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+type AuditHandler struct {
+	storage storage.AuditRepository
+	log     *slog.Logger
+}
+
+func NewAuditHandler(storage storage.AuditRepository, log *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := models.AuditFilter{
+		Actor:    c.Query("actor"),
+		EntityID: c.Query("entity_id"),
+		Cursor:   c.Query("cursor"),
+	}
+
+	if raw := c.Query("operation"); raw != "" {
+		for _, operation := range strings.Split(raw, ",") {
+			filter.Operations = append(filter.Operations, models.AuditOperation(strings.TrimSpace(operation)))
+		}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			h.log.Warn("invalid limit parameter", slog.String("limit", raw))
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "limit must be a positive integer"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	timeParams := map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	}
+	for name, dest := range timeParams {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			h.log.Warn("invalid date parameter", slog.String("param", name), slog.String("value", raw))
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", name+" must be an RFC 3339 timestamp"))
+			return
+		}
+		*dest = &t
+	}
+
+	if c.Query("format") == "ndjson" || strings.Contains(c.GetHeader("Accept"), ndjsonContentType) {
+		h.export(c, filter)
+		return
+	}
+
+	page, err := h.storage.ListAudit(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(page))
+}
+
+// export отдаёт все подходящие записи потоком, по одному JSON-объекту на строку
+func (h *AuditHandler) export(c *gin.Context, filter models.AuditFilter) {
+	encoder := json.NewEncoder(c.Writer)
+	count := 0
+	err := h.storage.ExportAudit(c.Request.Context(), filter, func(entry models.AuditEntry) error {
+		if count == 0 {
+			c.Header("Content-Type", ndjsonContentType)
+			c.Header("Content-Disposition", `attachment; filename="audit.ndjson"`)
+		}
+		count++
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		// после начала потока заголовки уже отправлены, ошибку можно только залогировать
+		if c.Writer.Written() {
+			h.log.Error("audit export interrupted", sl.Err(err), slog.Int("exported", count))
+			return
+		}
+		c.Error(err)
+		return
+	}
+
+	if count == 0 {
+		c.Header("Content-Type", ndjsonContentType)
+		c.Status(http.StatusOK)
+	}
+	h.log.Debug("audit log exported", slog.Int("count", count))
+}
@@ -0,0 +1,76 @@
+package health_handler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"review-assignment/internal/database"
+	"review-assignment/internal/lib/http/response"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/migrations"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	db         *sql.DB
+	migrations *migrations.Migrator
+	log        *slog.Logger
+	timeout    time.Duration
+	draining   atomic.Bool
+}
+
+func NewHealthHandler(db *sql.DB, migrations *migrations.Migrator, log *slog.Logger, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{
+		db:         db,
+		migrations: migrations,
+		log:        log,
+		timeout:    timeout,
+	}
+}
+
+// SetDraining переводит сервис в режим остановки: readiness начинает отвечать 503,
+// чтобы балансировщик перестал направлять новые запросы
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Live отвечает, пока процесс способен обрабатывать HTTP, и не обращается к БД
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, response.HealthResponse())
+}
+
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, response.NewErrorResponse("NOT_READY", "server is shutting down"))
+		return
+	}
+
+	// у хранилища в памяти нет БД, которую нужно проверять
+	if h.db != nil {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+		defer cancel()
+
+		if err := database.HealthCheck(ctx, h.db); err != nil {
+			h.log.Warn("database is unavailable", sl.Err(err))
+			c.JSON(http.StatusServiceUnavailable, response.NewErrorResponse("NOT_READY", "database is unavailable"))
+			return
+		}
+
+		if err := h.migrations.Check(ctx); err != nil {
+			h.log.Warn("database schema is not migrated", sl.Err(err))
+			c.JSON(http.StatusServiceUnavailable, response.NewErrorResponse("NOT_READY", "database schema is not migrated"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ready",
+		"timestamp": time.Now(),
+	})
+}
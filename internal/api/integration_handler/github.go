@@ -0,0 +1,119 @@
+package integration_handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"review-assignment/internal/apperr"
+	"review-assignment/internal/lib/hmacsig"
+	"review-assignment/internal/lib/http/response"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/models"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		ID     int64      `json:"id"`
+		Title  string     `json:"title"`
+		Draft  bool       `json:"draft"`
+		Merged bool       `json:"merged"`
+		User   githubUser `json:"user"`
+	} `json:"pull_request"`
+	Sender githubUser `json:"sender"`
+}
+
+// GitHub принимает события pull_request из GitHub и переводит их в операции над PR
+func (h *IntegrationHandler) GitHub(c *gin.Context) {
+	body, err := readBody(c)
+	if err != nil {
+		h.log.Error("failed to read body", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if h.cfg.GitHubSecret == "" {
+		c.Error(errGitHubNotConfigured)
+		return
+	}
+	if !hmacsig.Verify(h.cfg.GitHubSecret, body, c.GetHeader("X-Hub-Signature-256")) {
+		c.Error(apperr.ErrInvalidSignature)
+		return
+	}
+
+	event := c.GetHeader("X-GitHub-Event")
+	deliveryID := c.GetHeader("X-GitHub-Delivery")
+
+	switch event {
+	case "ping":
+		h.log.Info("github ping received", slog.String("delivery_id", deliveryID))
+		c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"pong": true}))
+		return
+	case "pull_request":
+	default:
+		c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"ignored": true, "event": event}))
+		return
+	}
+
+	var payload githubPullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil || payload.PullRequest.ID == 0 {
+		h.log.Warn("invalid github payload", slog.String("delivery_id", deliveryID))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "invalid pull_request payload"))
+		return
+	}
+
+	switch payload.Action {
+	case "opened", "ready_for_review", "closed", "reopened":
+	default:
+		c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"ignored": true, "action": payload.Action}))
+		return
+	}
+
+	prID := "gh-" + strconv.FormatInt(payload.PullRequest.ID, 10)
+	actor := "github:" + payload.Sender.Login
+
+	h.deliver(c, models.ProviderGitHub, deliveryID, event, func(ctx context.Context) (gin.H, error) {
+		var pr *models.PullRequest
+		var err error
+
+		switch payload.Action {
+		case "opened":
+			var authorID string
+			authorID, err = h.storage.ResolveExternalAccount(ctx, models.ProviderGitHub, payload.PullRequest.User.Login)
+			if err != nil {
+				return nil, err
+			}
+			pr, err = h.storage.CreatePR(ctx, models.CreatePRRequest{
+				ID:       prID,
+				Name:     prName(payload.PullRequest.Title),
+				AuthorID: authorID,
+				Draft:    payload.PullRequest.Draft,
+			}, actor)
+		case "ready_for_review":
+			pr, err = h.storage.MarkReady(ctx, prID, actor)
+		case "closed":
+			if payload.PullRequest.Merged {
+				pr, err = h.storage.MergeExternalPR(ctx, prID, actor)
+			} else {
+				pr, err = h.storage.ClosePR(ctx, prID, actor)
+			}
+		case "reopened":
+			pr, err = h.storage.ReopenPR(ctx, prID, actor)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return gin.H{"action": payload.Action, "pr": pr}, nil
+	})
+}
@@ -0,0 +1,140 @@
+package integration_handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"review-assignment/internal/lib/http/response"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/models"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+const gitlabMergeRequestHook = "Merge Request Hook"
+
+type gitlabBoolChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectAttributes struct {
+		ID             int64  `json:"id"`
+		AuthorID       int64  `json:"author_id"`
+		Title          string `json:"title"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft          *gitlabBoolChange `json:"draft"`
+		WorkInProgress *gitlabBoolChange `json:"work_in_progress"`
+	} `json:"changes"`
+}
+
+// readyForReview сообщает, снят ли с MR статус draft этим обновлением
+func (e *gitlabMergeRequestEvent) readyForReview() bool {
+	change := e.Changes.Draft
+	if change == nil {
+		change = e.Changes.WorkInProgress
+	}
+	return change != nil && change.Previous && !change.Current
+}
+
+// GitLab принимает Merge Request Hook из GitLab и переводит его в операции над PR
+func (h *IntegrationHandler) GitLab(c *gin.Context) {
+	if h.cfg.GitLabToken == "" {
+		c.Error(errGitLabNotConfigured)
+		return
+	}
+	token := c.GetHeader("X-Gitlab-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.GitLabToken)) != 1 {
+		c.Error(errInvalidGitLabToken)
+		return
+	}
+
+	event := c.GetHeader("X-Gitlab-Event")
+	deliveryID := c.GetHeader("X-Gitlab-Event-UUID")
+
+	if event != gitlabMergeRequestHook {
+		c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"ignored": true, "event": event}))
+		return
+	}
+
+	body, err := readBody(c)
+	if err != nil {
+		h.log.Error("failed to read body", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	var payload gitlabMergeRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil || payload.ObjectAttributes.ID == 0 || payload.ObjectAttributes.AuthorID == 0 {
+		h.log.Warn("invalid gitlab payload", slog.String("delivery_id", deliveryID))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "invalid merge request payload"))
+		return
+	}
+
+	action := payload.ObjectAttributes.Action
+	switch {
+	case action == "open", action == "merge", action == "close", action == "reopen":
+	case action == "update" && payload.readyForReview():
+		action = "ready"
+	default:
+		c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"ignored": true, "action": action}))
+		return
+	}
+
+	prID := "gl-" + strconv.FormatInt(payload.ObjectAttributes.ID, 10)
+	actor := "gitlab:" + payload.User.Username
+
+	h.deliver(c, models.ProviderGitLab, deliveryID, event, func(ctx context.Context) (gin.H, error) {
+		var pr *models.PullRequest
+		var err error
+
+		switch action {
+		case "open":
+			// user — тот, кто вызвал хук (бот, мейнтейнер), а не обязательно автор MR
+			var authorID string
+			authorID, err = h.storage.ResolveExternalAccountByID(ctx, models.ProviderGitLab, strconv.FormatInt(payload.ObjectAttributes.AuthorID, 10))
+			if err != nil {
+				return nil, err
+			}
+			pr, err = h.storage.CreatePR(ctx, models.CreatePRRequest{
+				ID:       prID,
+				Name:     prName(payload.ObjectAttributes.Title),
+				AuthorID: authorID,
+				Draft:    payload.ObjectAttributes.Draft || payload.ObjectAttributes.WorkInProgress,
+			}, actor)
+		case "ready":
+			pr, err = h.storage.MarkReady(ctx, prID, actor)
+		case "merge":
+			pr, err = h.storage.MergeExternalPR(ctx, prID, actor)
+		case "close":
+			pr, err = h.storage.ClosePR(ctx, prID, actor)
+		case "reopen":
+			pr, err = h.storage.ReopenPR(ctx, prID, actor)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// бот синхронизирует ревьюверов обратно в GitLab по их username
+		reviewers, err := h.storage.ExternalReviewers(ctx, models.ProviderGitLab, pr.AssignedReviewers)
+		if err != nil {
+			return nil, err
+		}
+
+		return gin.H{"action": action, "pr": pr, "reviewers": reviewers}, nil
+	})
+}
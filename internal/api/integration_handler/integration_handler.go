@@ -0,0 +1,171 @@
+package integration_handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"review-assignment/internal/apperr"
+	"review-assignment/internal/lib/http/response"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxPayloadSize = 25 << 20
+	maxPRNameLen   = 200
+
+	defaultDeliveryLease = time.Minute
+)
+
+var (
+	errGitHubNotConfigured = apperr.ErrNotConfigured.WithMessage("github integration is not configured")
+	errGitLabNotConfigured = apperr.ErrNotConfigured.WithMessage("gitlab integration is not configured")
+	errInvalidGitLabToken  = apperr.ErrInvalidSignature.WithMessage("token does not match")
+)
+
+type Config struct {
+	GitHubSecret string
+	GitLabToken  string
+	// DeliveryLease — сколько входящая доставка считается обрабатываемой; не меньше таймаута запроса
+	DeliveryLease time.Duration
+}
+
+type IntegrationHandler struct {
+	storage storage.Repository
+	log     *slog.Logger
+	cfg     Config
+}
+
+func NewIntegrationHandler(storage storage.Repository, log *slog.Logger, cfg Config) *IntegrationHandler {
+	if cfg.DeliveryLease <= 0 {
+		cfg.DeliveryLease = defaultDeliveryLease
+	}
+
+	return &IntegrationHandler{
+		storage: storage,
+		log:     log,
+		cfg:     cfg,
+	}
+}
+
+func (h *IntegrationHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.storage.ListExternalAccounts(c.Request.Context(), models.IntegrationProvider(c.Query("provider")))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"accounts": accounts}))
+}
+
+func (h *IntegrationHandler) SetAccount(c *gin.Context) {
+	var req models.SetExternalAccountRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	account, err := h.storage.SetExternalAccount(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("external account linked",
+		slog.String("provider", string(account.Provider)),
+		slog.String("login", account.Login),
+		slog.String("user_id", account.UserID))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"account": account}))
+}
+
+func (h *IntegrationHandler) RemoveAccount(c *gin.Context) {
+	var req models.RemoveExternalAccountRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if err := h.storage.RemoveExternalAccount(c.Request.Context(), req.Provider, req.Login); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("external account unlinked",
+		slog.String("provider", string(req.Provider)),
+		slog.String("login", req.Login))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"provider": req.Provider,
+		"login":    req.Login,
+	}))
+}
+
+// deliver обрабатывает входящее событие один раз на deliveryID. На время обработки доставка
+// захватывается: повтор, пришедший в это время, получает 409 DELIVERY_IN_PROGRESS и будет
+// отправлен снова. Если обработка не удалась, захват снимается; если процесс упал, захват истекает.
+func (h *IntegrationHandler) deliver(c *gin.Context, provider models.IntegrationProvider, deliveryID, event string, process func(ctx context.Context) (gin.H, error)) {
+	log := h.log.With(
+		slog.String("provider", string(provider)),
+		slog.String("event", event),
+		slog.String("delivery_id", deliveryID))
+
+	ctx := c.Request.Context()
+
+	if deliveryID != "" {
+		claimed, err := h.storage.ClaimDelivery(ctx, provider, deliveryID, event, h.cfg.DeliveryLease)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if !claimed {
+			log.Info("duplicate delivery skipped")
+			c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"duplicate": true}))
+			return
+		}
+	}
+
+	result, err := process(ctx)
+	if err != nil {
+		if deliveryID != "" {
+			// отметку нужно снять, даже если запрос отменён: иначе повторная доставка будет пропущена
+			if releaseErr := h.storage.ReleaseDelivery(context.WithoutCancel(ctx), provider, deliveryID); releaseErr != nil {
+				log.Error("failed to release delivery", sl.Err(releaseErr))
+			}
+		}
+		c.Error(err)
+		return
+	}
+
+	if deliveryID != "" {
+		// изменения уже сохранены: без отметки захват истечёт и повтор обработается ещё раз
+		if err := h.storage.CompleteDelivery(context.WithoutCancel(ctx), provider, deliveryID); err != nil {
+			log.Error("failed to complete delivery", sl.Err(err))
+		}
+	}
+
+	log.Info("integration event processed")
+	c.JSON(http.StatusOK, response.NewSuccessResponse(result))
+}
+
+func readBody(c *gin.Context) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(c.Request.Body, maxPayloadSize))
+}
+
+// prName обрезает заголовок до длины колонки pull_request_name
+func prName(title string) string {
+	if utf8.RuneCountInString(title) <= maxPRNameLen {
+		return title
+	}
+	return string([]rune(title)[:maxPRNameLen])
+}
@@ -1,9 +1,13 @@
 package pr_handler
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"review-assignment/internal/lib/http/actor"
 	"review-assignment/internal/lib/http/response"
 	"review-assignment/internal/lib/logger/sl"
 	"review-assignment/internal/models"
@@ -15,11 +19,11 @@ import (
 )
 
 type PRHandler struct {
-	storage *storage.Storage
+	storage storage.PRRepository
 	log     *slog.Logger
 }
 
-func NewPRHandler(storage *storage.Storage, log *slog.Logger) *PRHandler {
+func NewPRHandler(storage storage.PRRepository, log *slog.Logger) *PRHandler {
 	return &PRHandler{
 		storage: storage,
 		log:     log,
@@ -27,8 +31,6 @@ func NewPRHandler(storage *storage.Storage, log *slog.Logger) *PRHandler {
 }
 
 func (h *PRHandler) CreatePR(c *gin.Context) {
-	const op = "handlers.pr.CreatePR"
-
 	var req models.CreatePRRequest
 
 	if err := c.BindJSON(&req); err != nil {
@@ -37,19 +39,9 @@ func (h *PRHandler) CreatePR(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.storage.CreatePR(req)
+	pr, err := h.storage.CreatePR(c.Request.Context(), req, actor.FromRequest(c))
 	if err != nil {
-		switch {
-		case strings.Contains(err.Error(), "AUTHOR_NOT_FOUND"):
-			h.log.Warn("author not found", slog.String("author_id", req.AuthorID))
-			c.JSON(http.StatusNotFound, response.NewErrorResponse("NOT_FOUND", "author not found"))
-		case strings.Contains(err.Error(), "PR_EXISTS"):
-			h.log.Warn("PR already exists", slog.String("pr_id", req.ID))
-			c.JSON(http.StatusConflict, response.NewErrorResponse("PR_EXISTS", "PR already exists"))
-		default:
-			h.log.Error("failed to create PR", sl.Err(err), slog.String("pr_id", req.ID))
-			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("INTERNAL_ERROR", err.Error()))
-		}
+		c.Error(err)
 		return
 	}
 
@@ -61,8 +53,6 @@ func (h *PRHandler) CreatePR(c *gin.Context) {
 }
 
 func (h *PRHandler) MergePR(c *gin.Context) {
-	const op = "handlers.pr.MergePR"
-
 	var req struct {
 		PRID string `json:"pull_request_id" binding:"required"`
 	}
@@ -73,15 +63,9 @@ func (h *PRHandler) MergePR(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.storage.MergePR(req.PRID)
+	pr, err := h.storage.MergePR(c.Request.Context(), req.PRID, actor.FromRequest(c))
 	if err != nil {
-		if strings.Contains(err.Error(), "NOT_FOUND") {
-			h.log.Warn("PR not found", slog.String("pr_id", req.PRID))
-			c.JSON(http.StatusNotFound, response.NewErrorResponse("NOT_FOUND", "PR not found"))
-			return
-		}
-		h.log.Error("failed to merge PR", sl.Err(err), slog.String("pr_id", req.PRID))
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("INTERNAL_ERROR", err.Error()))
+		c.Error(err)
 		return
 	}
 
@@ -90,8 +74,6 @@ func (h *PRHandler) MergePR(c *gin.Context) {
 }
 
 func (h *PRHandler) ReassignReviewer(c *gin.Context) {
-	const op = "handlers.pr.ReassignReviewer"
-
 	var req models.ReassignRequest
 
 	if err := c.BindJSON(&req); err != nil {
@@ -100,33 +82,9 @@ func (h *PRHandler) ReassignReviewer(c *gin.Context) {
 		return
 	}
 
-	pr, newReviewer, err := h.storage.ReassignReviewer(req)
+	pr, newReviewer, err := h.storage.ReassignReviewer(c.Request.Context(), req, actor.FromRequest(c))
 	if err != nil {
-		switch {
-		case strings.Contains(err.Error(), "NOT_FOUND"):
-			h.log.Warn("PR or user not found",
-				slog.String("pr_id", req.PRID),
-				slog.String("old_reviewer", req.OldReviewer))
-			c.JSON(http.StatusNotFound, response.NewErrorResponse("NOT_FOUND", "PR or user not found"))
-		case strings.Contains(err.Error(), "PR_MERGED"):
-			h.log.Warn("cannot reassign on merged PR", slog.String("pr_id", req.PRID))
-			c.JSON(http.StatusConflict, response.NewErrorResponse("PR_MERGED", "cannot reassign on merged PR"))
-		case strings.Contains(err.Error(), "NOT_ASSIGNED"):
-			h.log.Warn("reviewer not assigned to PR",
-				slog.String("pr_id", req.PRID),
-				slog.String("old_reviewer", req.OldReviewer))
-			c.JSON(http.StatusConflict, response.NewErrorResponse("NOT_ASSIGNED", "reviewer is not assigned to this PR"))
-		case strings.Contains(err.Error(), "NO_CANDIDATE"):
-			h.log.Warn("no replacement candidate found",
-				slog.String("pr_id", req.PRID),
-				slog.String("old_reviewer", req.OldReviewer))
-			c.JSON(http.StatusConflict, response.NewErrorResponse("NO_CANDIDATE", "no active replacement candidate in team"))
-		default:
-			h.log.Error("failed to reassign reviewer", sl.Err(err),
-				slog.String("pr_id", req.PRID),
-				slog.String("old_reviewer", req.OldReviewer))
-			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("INTERNAL_ERROR", err.Error()))
-		}
+		c.Error(err)
 		return
 	}
 
@@ -140,8 +98,170 @@ func (h *PRHandler) ReassignReviewer(c *gin.Context) {
 	}))
 }
 
-// Health check
-func (h *PRHandler) Health(c *gin.Context) {
-	h.log.Debug("health check requested")
-	c.JSON(http.StatusOK, response.HealthResponse())
+func (h *PRHandler) ClosePR(c *gin.Context) {
+	h.changeStatus(c, "handlers.pr.ClosePR", h.storage.ClosePR)
+}
+
+func (h *PRHandler) MarkReady(c *gin.Context) {
+	h.changeStatus(c, "handlers.pr.MarkReady", h.storage.MarkReady)
+}
+
+func (h *PRHandler) ReopenPR(c *gin.Context) {
+	h.changeStatus(c, "handlers.pr.ReopenPR", h.storage.ReopenPR)
+}
+
+func (h *PRHandler) changeStatus(c *gin.Context, op string, change func(ctx context.Context, prID, actor string) (*models.PullRequest, error)) {
+	var req struct {
+		PRID string `json:"pull_request_id" binding:"required"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err), slog.String("op", op))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	pr, err := change(c.Request.Context(), req.PRID, actor.FromRequest(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("PR status changed",
+		slog.String("op", op),
+		slog.String("pr_id", req.PRID),
+		slog.String("status", string(pr.Status)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"pr": pr}))
+}
+
+func (h *PRHandler) SubmitReview(c *gin.Context) {
+	var req models.SubmitReviewRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	review, err := h.storage.SubmitReview(c.Request.Context(), req, actor.FromRequest(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("review submitted",
+		slog.String("pr_id", req.PRID),
+		slog.String("reviewer_id", req.ReviewerID),
+		slog.String("state", string(req.State)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"review": review}))
+}
+
+func (h *PRHandler) ListOverdue(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			h.log.Warn("invalid limit parameter", slog.String("limit", raw))
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "limit must be a positive integer"))
+			return
+		}
+		limit = parsed
+	}
+
+	overdue, err := h.storage.ListOverdueReviews(c.Request.Context(), time.Now())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	events, err := h.storage.ListSLAEvents(c.Request.Context(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"overdue": overdue,
+		"actions": events,
+	}))
+}
+
+func (h *PRHandler) GetPR(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		h.log.Warn("pull_request_id parameter is missing")
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "pull_request_id parameter is required"))
+		return
+	}
+
+	details, err := h.storage.GetPR(c.Request.Context(), prID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(details))
+}
+
+func (h *PRHandler) ListPRs(c *gin.Context) {
+	filter := models.PRListFilter{
+		AuthorID:     c.Query("author_id"),
+		ReviewerID:   c.Query("reviewer_id"),
+		TeamName:     c.Query("team_name"),
+		NameContains: c.Query("name"),
+		Cursor:       c.Query("cursor"),
+	}
+
+	if raw := c.Query("status"); raw != "" {
+		for _, value := range strings.Split(raw, ",") {
+			status := models.PRStatus(strings.TrimSpace(value))
+			switch status {
+			case models.StatusDraft, models.StatusOpen, models.StatusMerged, models.StatusClosed:
+			default:
+				h.log.Warn("invalid status parameter", slog.String("status", raw))
+				c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "status must be a comma-separated list of DRAFT, OPEN, MERGED, CLOSED"))
+				return
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			h.log.Warn("invalid limit parameter", slog.String("limit", raw))
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "limit must be a positive integer"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	timeParams := map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"merged_from":  &filter.MergedFrom,
+		"merged_to":    &filter.MergedTo,
+	}
+	for name, dest := range timeParams {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			h.log.Warn("invalid date parameter", slog.String("param", name), slog.String("value", raw))
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", name+" must be an RFC 3339 timestamp"))
+			return
+		}
+		*dest = &t
+	}
+
+	page, err := h.storage.ListPRs(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Debug("PRs listed", slog.Int("count", len(page.PullRequests)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(page))
 }
@@ -2,8 +2,8 @@ package team_handler
 
 import (
 	"net/http"
-	"strings"
 
+	"review-assignment/internal/lib/http/actor"
 	"review-assignment/internal/lib/http/response"
 	"review-assignment/internal/lib/logger/sl"
 	"review-assignment/internal/models"
@@ -15,11 +15,11 @@ import (
 )
 
 type TeamHandler struct {
-	storage *storage.Storage
+	storage storage.TeamRepository
 	log     *slog.Logger
 }
 
-func NewTeamHandler(storage *storage.Storage, log *slog.Logger) *TeamHandler {
+func NewTeamHandler(storage storage.TeamRepository, log *slog.Logger) *TeamHandler {
 	return &TeamHandler{
 		storage: storage,
 		log:     log,
@@ -27,8 +27,6 @@ func NewTeamHandler(storage *storage.Storage, log *slog.Logger) *TeamHandler {
 }
 
 func (h *TeamHandler) CreateTeam(c *gin.Context) {
-	const op = "handlers.team.CreateTeam"
-
 	var req models.CreateTeamRequest
 
 	if err := c.BindJSON(&req); err != nil {
@@ -38,18 +36,13 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 	}
 
 	team := models.Team{
-		Name:    req.Name,
-		Members: req.Members,
+		Name:     req.Name,
+		Strategy: req.Strategy,
+		Members:  req.Members,
 	}
 
-	if err := h.storage.CreateTeam(team); err != nil {
-		if strings.Contains(err.Error(), "TEAM_EXISTS") {
-			h.log.Warn("team already exists", slog.String("team_name", req.Name))
-			c.JSON(http.StatusBadRequest, response.NewErrorResponse("TEAM_EXISTS", "team already exists"))
-			return
-		}
-		h.log.Error("failed to create team", sl.Err(err))
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("INTERNAL_ERROR", err.Error()))
+	if err := h.storage.CreateTeam(c.Request.Context(), team, actor.FromRequest(c)); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -58,8 +51,6 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 }
 
 func (h *TeamHandler) GetTeam(c *gin.Context) {
-	const op = "handlers.team.GetTeam"
-
 	teamName := c.Query("team_name")
 	if teamName == "" {
 		h.log.Warn("team_name parameter is missing")
@@ -67,18 +58,141 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 		return
 	}
 
-	team, err := h.storage.GetTeam(teamName)
+	team, err := h.storage.GetTeam(c.Request.Context(), teamName)
 	if err != nil {
-		if strings.Contains(err.Error(), "NOT_FOUND") {
-			h.log.Warn("team not found", slog.String("team_name", teamName))
-			c.JSON(http.StatusNotFound, response.NewErrorResponse("NOT_FOUND", "team not found"))
-			return
-		}
-		h.log.Error("failed to get team", sl.Err(err), slog.String("team_name", teamName))
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("INTERNAL_ERROR", err.Error()))
+		c.Error(err)
 		return
 	}
 
 	h.log.Debug("team retrieved successfully", slog.String("team_name", teamName))
 	c.JSON(http.StatusOK, response.NewSuccessResponse(team))
 }
+
+func (h *TeamHandler) GetPolicy(c *gin.Context) {
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		h.log.Warn("team_name parameter is missing")
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "team_name parameter is required"))
+		return
+	}
+
+	policy, err := h.storage.GetTeamPolicy(c.Request.Context(), teamName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"policy": policy}))
+}
+
+func (h *TeamHandler) SetPolicy(c *gin.Context) {
+	var req models.SetTeamPolicyRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	policy, err := h.storage.SetTeamPolicy(c.Request.Context(), req, actor.FromRequest(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("team policy updated",
+		slog.String("team_name", policy.TeamName),
+		slog.Int("min_reviewers", policy.MinReviewers),
+		slog.Int("max_reviewers", policy.MaxReviewers),
+		slog.String("strategy", string(policy.Strategy)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"policy": policy}))
+}
+
+func (h *TeamHandler) DeactivateUsers(c *gin.Context) {
+	var req models.TeamDeactivationRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	result, err := h.storage.DeactivateTeamUsers(c.Request.Context(), req.TeamName, req.UserIDs, actor.FromRequest(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("team users deactivated",
+		slog.String("team_name", req.TeamName),
+		slog.Int("deactivated", len(result.Deactivated)),
+		slog.Int("reassigned", len(result.Reassigned)),
+		slog.Int("not_reassigned", len(result.NotReassigned)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(result))
+}
+
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	var req models.RemoveTeamMemberRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	result, err := h.storage.RemoveTeamMember(c.Request.Context(), req.TeamName, req.UserID, actor.FromRequest(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("team member removed",
+		slog.String("team_name", req.TeamName),
+		slog.String("user_id", req.UserID),
+		slog.Int("reassigned", len(result.Reassigned)),
+		slog.Int("not_reassigned", len(result.NotReassigned)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(result))
+}
+
+func (h *TeamHandler) MoveMember(c *gin.Context) {
+	var req models.MoveTeamMemberRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	result, err := h.storage.MoveTeamMember(c.Request.Context(), req, actor.FromRequest(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("team member moved",
+		slog.String("user_id", req.UserID),
+		slog.String("from_team", result.FromTeam),
+		slog.String("to_team", req.ToTeam),
+		slog.String("open_reviews", string(req.OpenReviews)),
+		slog.Int("reassigned", len(result.Reassigned)),
+		slog.Int("not_reassigned", len(result.NotReassigned)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(result))
+}
+
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	var req models.DeleteTeamRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if err := h.storage.DeleteTeam(c.Request.Context(), req.TeamName, actor.FromRequest(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("team deleted", slog.String("team_name", req.TeamName))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"team_name": req.TeamName}))
+}
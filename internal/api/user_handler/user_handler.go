@@ -2,8 +2,8 @@ package user_handler
 
 import (
 	"net/http"
-	"strings"
 
+	"review-assignment/internal/lib/http/actor"
 	"review-assignment/internal/lib/http/response"
 	"review-assignment/internal/lib/logger/sl"
 	"review-assignment/internal/models"
@@ -15,11 +15,11 @@ import (
 )
 
 type UserHandler struct {
-	storage *storage.Storage
+	storage storage.UserRepository
 	log     *slog.Logger
 }
 
-func NewUserHandler(storage *storage.Storage, log *slog.Logger) *UserHandler {
+func NewUserHandler(storage storage.UserRepository, log *slog.Logger) *UserHandler {
 	return &UserHandler{
 		storage: storage,
 		log:     log,
@@ -27,8 +27,6 @@ func NewUserHandler(storage *storage.Storage, log *slog.Logger) *UserHandler {
 }
 
 func (h *UserHandler) SetUserActive(c *gin.Context) {
-	const op = "handlers.user.SetUserActive"
-
 	var req struct {
 		UserID   string `json:"user_id" binding:"required"`
 		IsActive bool   `json:"is_active"`
@@ -42,27 +40,25 @@ func (h *UserHandler) SetUserActive(c *gin.Context) {
 		return
 	}
 
-	user, err := h.storage.SetUserActive(req.UserID, req.IsActive)
+	user, report, err := h.storage.SetUserActive(c.Request.Context(), req.UserID, req.IsActive, actor.FromRequest(c))
 	if err != nil {
-		if strings.Contains(err.Error(), "NOT_FOUND") {
-			h.log.Warn("user not found", slog.String("user_id", req.UserID))
-			c.JSON(http.StatusNotFound, response.NewErrorResponse("NOT_FOUND", "user not found"))
-			return
-		}
-		h.log.Error("failed to set user active", sl.Err(err), slog.String("user_id", req.UserID))
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("INTERNAL_ERROR", err.Error()))
+		c.Error(err)
 		return
 	}
 
 	h.log.Info("user activity updated",
 		slog.String("user_id", req.UserID),
-		slog.Bool("is_active", req.IsActive))
-	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"user": user}))
+		slog.Bool("is_active", req.IsActive),
+		slog.Int("reassigned", len(report.Reassigned)),
+		slog.Int("not_reassigned", len(report.NotReassigned)))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"user":           user,
+		"reassigned":     report.Reassigned,
+		"not_reassigned": report.NotReassigned,
+	}))
 }
 
 func (h *UserHandler) GetUserReviews(c *gin.Context) {
-	const op = "handlers.user.GetUserReviews"
-
 	userID := c.Query("user_id")
 	if userID == "" {
 		h.log.Warn("user_id parameter is missing")
@@ -70,15 +66,9 @@ func (h *UserHandler) GetUserReviews(c *gin.Context) {
 		return
 	}
 
-	prs, err := h.storage.GetUserReviews(userID)
+	prs, err := h.storage.GetUserReviews(c.Request.Context(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "NOT_FOUND") {
-			h.log.Warn("user not found", slog.String("user_id", userID))
-			c.JSON(http.StatusNotFound, response.NewErrorResponse("NOT_FOUND", "user not found"))
-			return
-		}
-		h.log.Error("failed to get user reviews", sl.Err(err), slog.String("user_id", userID))
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("INTERNAL_ERROR", err.Error()))
+		c.Error(err)
 		return
 	}
 
@@ -100,3 +90,67 @@ func (h *UserHandler) GetUserReviews(c *gin.Context) {
 		"pull_requests": prsShort,
 	}))
 }
+
+func (h *UserHandler) ListOutOfOffice(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		h.log.Warn("user_id parameter is missing")
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "user_id parameter is required"))
+		return
+	}
+
+	periods, err := h.storage.ListOutOfOffice(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"user_id": userID,
+		"periods": periods,
+	}))
+}
+
+func (h *UserHandler) AddOutOfOffice(c *gin.Context) {
+	var req models.AddOutOfOfficeRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	period, err := h.storage.AddOutOfOffice(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("out-of-office period added",
+		slog.String("user_id", req.UserID),
+		slog.Int64("ooo_id", period.ID))
+	c.JSON(http.StatusCreated, response.NewSuccessResponse(gin.H{"period": period}))
+}
+
+func (h *UserHandler) RemoveOutOfOffice(c *gin.Context) {
+	var req models.RemoveOutOfOfficeRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if err := h.storage.RemoveOutOfOffice(c.Request.Context(), req.UserID, req.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("out-of-office period removed",
+		slog.String("user_id", req.UserID),
+		slog.Int64("ooo_id", req.ID))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"user_id": req.UserID,
+		"ooo_id":  req.ID,
+	}))
+}
@@ -0,0 +1,136 @@
+package webhook_handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"review-assignment/internal/lib/http/response"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	storage storage.WebhookRepository
+	log     *slog.Logger
+}
+
+func NewWebhookHandler(storage storage.WebhookRepository, log *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (h *WebhookHandler) Add(c *gin.Context) {
+	var req models.AddWebhookRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	sub, err := h.storage.AddWebhook(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("webhook subscription added",
+		slog.Int64("subscription_id", sub.ID),
+		slog.String("url", sub.URL))
+	c.JSON(http.StatusCreated, response.NewSuccessResponse(gin.H{"subscription": sub}))
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	subs, err := h.storage.ListWebhooks(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"subscriptions": subs}))
+}
+
+func (h *WebhookHandler) Remove(c *gin.Context) {
+	var req models.WebhookIDRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	if err := h.storage.RemoveWebhook(c.Request.Context(), req.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("webhook subscription removed", slog.Int64("subscription_id", req.ID))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"subscription_id": req.ID}))
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var subscriptionID int64
+	if raw := c.Query("subscription_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			h.log.Warn("invalid subscription_id parameter", slog.String("subscription_id", raw))
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "subscription_id must be a positive integer"))
+			return
+		}
+		subscriptionID = id
+	}
+
+	status := models.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		h.log.Warn("invalid status parameter", slog.String("status", string(status)))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "status must be PENDING, DELIVERED or DEAD"))
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			h.log.Warn("invalid limit parameter", slog.String("limit", raw))
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "limit must be a positive integer"))
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.storage.ListWebhookDeliveries(c.Request.Context(), subscriptionID, status, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"deliveries": deliveries}))
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	var req models.RedeliverWebhookRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("failed to bind JSON", sl.Err(err))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+
+	delivery, err := h.storage.RedeliverWebhook(c.Request.Context(), req.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.log.Info("webhook delivery requeued", slog.Int64("delivery_id", req.ID))
+	c.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{"delivery": delivery}))
+}
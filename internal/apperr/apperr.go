@@ -0,0 +1,91 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+)
+
+// Коды ошибок API (см. ErrorResponse в openapi.yml)
+const (
+	CodeInvalidInput       = "INVALID_INPUT"
+	CodeNotFound           = "NOT_FOUND"
+	CodeTeamExists         = "TEAM_EXISTS"
+	CodeTeamNotEmpty       = "TEAM_NOT_EMPTY"
+	CodeMemberHasOpenPRs   = "MEMBER_HAS_OPEN_PRS"
+	CodePRExists           = "PR_EXISTS"
+	CodePRMerged           = "PR_MERGED"
+	CodePRNotOpen          = "PR_NOT_OPEN"
+	CodeNotAssigned        = "NOT_ASSIGNED"
+	CodeNoCandidate        = "NO_CANDIDATE"
+	CodeNotEnoughReviewers = "NOT_ENOUGH_REVIEWERS"
+	CodeNotEnoughApprovals = "NOT_ENOUGH_APPROVALS"
+	CodeInvalidTransition  = "INVALID_TRANSITION"
+	CodeUnknownAccount     = "UNKNOWN_ACCOUNT"
+	CodeInvalidSignature   = "INVALID_SIGNATURE"
+	CodeNotConfigured      = "NOT_CONFIGURED"
+	CodeDeliveryInProgress = "DELIVERY_IN_PROGRESS"
+	CodeConflict           = "CONFLICT"
+	CodeTimeout            = "TIMEOUT"
+	CodeCanceled           = "REQUEST_CANCELED"
+	CodeInternal           = "INTERNAL_ERROR"
+)
+
+var (
+	ErrTimeout          = New(CodeTimeout, "request timed out")
+	ErrCanceled         = New(CodeCanceled, "request canceled")
+	ErrInvalidSignature = New(CodeInvalidSignature, "signature does not match")
+	ErrNotConfigured    = New(CodeNotConfigured, "integration is not configured")
+)
+
+// Error — доменная ошибка с кодом API и сообщением для клиента.
+// Ошибки, созданные через WithMessage, совпадают по errors.Is со своей базовой ошибкой.
+type Error struct {
+	Code    string
+	Message string
+	base    *Error
+}
+
+func New(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// WithMessage создаёт уточнённую ошибку того же вида, например «team not found» для NOT_FOUND
+func (e *Error) WithMessage(message string) *Error {
+	return &Error{Code: e.Code, Message: message, base: e}
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	for base := e.base; base != nil; base = base.base {
+		if base == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup находит в цепочке доменную ошибку, а для ошибок PostgreSQL и SQLite подбирает подходящую по коду.
+// Возвращает nil, если ошибка неизвестна и должна считаться внутренней.
+func Lookup(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrCanceled
+	}
+	if appErr := fromDB(err); appErr != nil {
+		return appErr
+	}
+	return fromSQLite(err)
+}
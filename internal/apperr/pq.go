@@ -0,0 +1,58 @@
+package apperr
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Коды SQLSTATE, см. https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+	pqCheckViolation      = "23514"
+	pqNotNullViolation    = "23502"
+	pqStringTooLong       = "22001"
+	pqInvalidText         = "22P02"
+	pqQueryCanceled       = "57014"
+	pqSerialization       = "40001"
+	pqDeadlockDetected    = "40P01"
+)
+
+var (
+	errDuplicate    = New(CodeConflict, "resource already exists")
+	errReference    = New(CodeConflict, "referenced resource does not exist or is still in use")
+	errInvalidValue = New(CodeInvalidInput, "invalid field value")
+	errConcurrent   = New(CodeConflict, "concurrent update, please retry")
+)
+
+// IsUniqueViolation сообщает, нарушено ли ограничение уникальности constraint
+func IsUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqUniqueViolation && pqErr.Constraint == constraint
+	}
+	return isSQLiteUniqueViolation(err, constraint)
+}
+
+func fromDB(err error) *Error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return nil
+	}
+
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		return errDuplicate
+	case pqForeignKeyViolation:
+		return errReference
+	case pqCheckViolation, pqNotNullViolation, pqStringTooLong, pqInvalidText:
+		return errInvalidValue
+	case pqSerialization, pqDeadlockDetected:
+		return errConcurrent
+	case pqQueryCanceled:
+		// точная причина (таймаут или отмена клиентом) известна только по контексту запроса
+		return ErrCanceled
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package apperr
+
+import (
+	"errors"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var errSQLiteBusy = New(CodeConflict, "database is busy, please retry")
+
+// sqliteConstraint восстанавливает имя ограничения так, как его назвал бы PostgreSQL
+// (teams_pkey, table_col_key): SQLite сообщает только таблицу и колонки.
+func sqliteConstraint(e *sqlite.Error) string {
+	msg := e.Error()
+	const marker = "UNIQUE constraint failed: "
+	i := strings.Index(msg, marker)
+	if i < 0 {
+		return ""
+	}
+	msg = msg[i+len(marker):]
+	if j := strings.LastIndex(msg, " ("); j >= 0 {
+		msg = msg[:j]
+	}
+
+	var table string
+	var columns []string
+	for _, qualified := range strings.Split(msg, ", ") {
+		t, column, ok := strings.Cut(qualified, ".")
+		if !ok {
+			return ""
+		}
+		table = t
+		columns = append(columns, column)
+	}
+
+	if e.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+		return table + "_pkey"
+	}
+	return table + "_" + strings.Join(columns, "_") + "_key"
+}
+
+func isSQLiteUniqueViolation(err error, constraint string) bool {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	switch e.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return sqliteConstraint(e) == constraint
+	}
+	return false
+}
+
+func fromSQLite(err error) *Error {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return nil
+	}
+
+	switch e.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return errDuplicate
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return errReference
+	case sqlite3.SQLITE_CONSTRAINT_CHECK, sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		return errInvalidValue
+	case sqlite3.SQLITE_INTERRUPT:
+		return ErrCanceled
+	}
+	// busy_timeout истёк, а блокировку записи так и не удалось получить
+	switch e.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return errSQLiteBusy
+	}
+	return nil
+}
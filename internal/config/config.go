@@ -3,10 +3,20 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
+
+	"review-assignment/internal/lib/businesstime"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	StorageDriverPostgres = "postgres"
+	StorageDriverMemory   = "memory"
+	StorageDriverSQLite   = "sqlite"
+)
+
 type Config struct {
 	ServerPort string
 	DBHost     string
@@ -15,6 +25,32 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 	LogLevel   string
+
+	// StorageDriver — postgres, sqlite или memory (без БД, данные теряются при перезапуске)
+	StorageDriver string
+	// SQLitePath — файл БД для StorageDriver=sqlite
+	SQLitePath string
+
+	MigrateOnStart bool
+
+	RequestTimeout       time.Duration
+	ExportRequestTimeout time.Duration
+	ReadinessTimeout     time.Duration
+	ShutdownTimeout      time.Duration
+
+	SLACheckInterval time.Duration
+	// BusinessHours — рабочее время, в котором считается SLA
+	BusinessHours businesstime.Schedule
+
+	WebhookDispatchInterval time.Duration
+	WebhookBatchSize        int
+	WebhookMaxAttempts      int
+	WebhookBaseBackoff      time.Duration
+	WebhookMaxBackoff       time.Duration
+	WebhookTimeout          time.Duration
+
+	GitHubWebhookSecret string
+	GitLabWebhookToken  string
 }
 
 func Load() *Config {
@@ -29,6 +65,29 @@ func Load() *Config {
 		DBUser:     getEnv("DB_USER", "user"),
 		DBPassword: getEnv("DB_PASSWORD", "pass"),
 		DBName:     getEnv("DB_NAME", "reviewassignent"),
+
+		StorageDriver: getEnv("STORAGE_DRIVER", StorageDriverPostgres),
+		SQLitePath:    getEnv("SQLITE_PATH", "review-assignment.db"),
+
+		MigrateOnStart: getEnv("MIGRATE_ON_START", "true") == "true",
+
+		RequestTimeout:       getDuration("REQUEST_TIMEOUT", 10*time.Second),
+		ExportRequestTimeout: getDuration("EXPORT_REQUEST_TIMEOUT", 5*time.Minute),
+		ReadinessTimeout:     getDuration("READINESS_TIMEOUT", 2*time.Second),
+		ShutdownTimeout:      getDuration("SHUTDOWN_TIMEOUT", 20*time.Second),
+
+		SLACheckInterval: getDuration("SLA_CHECK_INTERVAL", time.Minute),
+		BusinessHours:    getSchedule("BUSINESS_HOURS", "BUSINESS_TIMEZONE"),
+
+		WebhookDispatchInterval: getDuration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second),
+		WebhookBatchSize:        getInt("WEBHOOK_BATCH_SIZE", 50),
+		WebhookMaxAttempts:      getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookBaseBackoff:      getDuration("WEBHOOK_BASE_BACKOFF", 10*time.Second),
+		WebhookMaxBackoff:       getDuration("WEBHOOK_MAX_BACKOFF", time.Hour),
+		WebhookTimeout:          getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+		GitHubWebhookSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitLabWebhookToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
 	}
 }
 
@@ -39,6 +98,47 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("invalid %s value %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("invalid %s value %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getSchedule читает рабочее окно вида 09:00-18:00 и часовой пояс IANA
+func getSchedule(windowKey, timezoneKey string) businesstime.Schedule {
+	window := getEnv(windowKey, "09:00-18:00")
+	timezone := getEnv(timezoneKey, "UTC")
+
+	schedule, err := businesstime.Parse(window, timezone)
+	if err != nil {
+		log.Printf("invalid %s/%s value %q/%q, using 09:00-18:00 UTC", windowKey, timezoneKey, window, timezone)
+		return businesstime.Default()
+	}
+	return schedule
+}
+
 func (c *Config) GetDBConnString() string {
 	return "host=" + c.DBHost +
 		" port=" + c.DBPort +
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -39,6 +40,6 @@ func ConnString(host, port, user, password, dbname string) string {
 }
 
 // HealthCheck проверяет доступность базы данных
-func HealthCheck(db *sql.DB) error {
-	return db.Ping()
+func HealthCheck(ctx context.Context, db *sql.DB) error {
+	return db.PingContext(ctx)
 }
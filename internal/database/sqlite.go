@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// NewSQLite открывает файл SQLite (pure-Go драйвер, cgo не нужен).
+//
+// Каждая транзакция начинается с BEGIN IMMEDIATE и сразу берёт блокировку записи,
+// поэтому пишущие транзакции выполняются строго по одной — это заменяет
+// SELECT ... FOR UPDATE, которым хранилище блокирует строки в PostgreSQL.
+// WAL позволяет читать параллельно с записью, а busy_timeout заставляет
+// ждать блокировку, а не сразу возвращать SQLITE_BUSY.
+func NewSQLite(path string) (*sql.DB, error) {
+	const op = "database.NewSQLite"
+
+	// busy_timeout идёт первым: переключение journal_mode на новом соединении
+	// тоже берёт блокировку и без него сразу завершается SQLITE_BUSY
+	params := url.Values{}
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(30 * time.Minute)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return db, nil
+}
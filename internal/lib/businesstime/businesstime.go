@@ -0,0 +1,106 @@
+package businesstime
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("business hours must look like 09:00-18:00 with start before end")
+
+// Schedule — рабочее время: с понедельника по пятницу от Start до End по часам Location.
+// Start и End отсчитываются от полуночи.
+type Schedule struct {
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// Default — 09:00–18:00 по UTC
+func Default() Schedule {
+	return Schedule{Start: 9 * time.Hour, End: 18 * time.Hour, Location: time.UTC}
+}
+
+// Parse разбирает окно вида "09:00-18:00" и имя часового пояса из базы IANA ("Europe/Moscow")
+func Parse(window, timezone string) (Schedule, error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return Schedule{}, ErrInvalidSchedule
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return Schedule{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if start >= end {
+		return Schedule{}, ErrInvalidSchedule
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return Schedule{Start: start, End: end, Location: location}, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, ErrInvalidSchedule
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// AddHours прибавляет к t заданное число рабочих часов. Время вне рабочего окна
+// и выходные пропускаются, результат возвращается в часовом поясе t.
+func (s Schedule) AddHours(t time.Time, hours int) time.Time {
+	remaining := time.Duration(hours) * time.Hour
+	current := t.In(s.location())
+
+	for remaining > 0 {
+		start, end := s.window(current)
+		if !isBusinessDay(current) || !current.Before(end) {
+			year, month, day := current.Date()
+			current = time.Date(year, month, day+1, 0, 0, 0, 0, current.Location())
+			continue
+		}
+		if current.Before(start) {
+			current = start
+		}
+
+		left := end.Sub(current)
+		if remaining <= left {
+			current = current.Add(remaining)
+			break
+		}
+		remaining -= left
+		current = end
+	}
+
+	return current.In(t.Location())
+}
+
+// window возвращает начало и конец рабочего окна в день t
+func (s Schedule) window(t time.Time) (time.Time, time.Time) {
+	year, month, day := t.Date()
+	at := func(offset time.Duration) time.Time {
+		return time.Date(year, month, day, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, t.Location())
+	}
+	return at(s.Start), at(s.End)
+}
+
+func (s Schedule) location() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
+}
+
+func isBusinessDay(t time.Time) bool {
+	day := t.Weekday()
+	return day != time.Saturday && day != time.Sunday
+}
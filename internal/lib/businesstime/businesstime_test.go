@@ -0,0 +1,103 @@
+package businesstime_test
+
+import (
+	"testing"
+	"time"
+
+	"review-assignment/internal/lib/businesstime"
+)
+
+func TestAddHours(t *testing.T) {
+	utc := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// 12 октября 2026 — понедельник, 16-е — пятница, 17–18-е — выходные
+	tests := []struct {
+		name  string
+		from  time.Time
+		hours int
+		want  time.Time
+	}{
+		{"inside window", utc(12, 10, 0), 4, utc(12, 14, 0)},
+		{"ends at closing time", utc(12, 9, 0), 9, utc(12, 18, 0)},
+		{"carries over to next day", utc(12, 16, 0), 4, utc(13, 11, 0)},
+		{"before opening", utc(12, 7, 30), 1, utc(12, 10, 0)},
+		{"after closing", utc(12, 20, 0), 1, utc(13, 10, 0)},
+		{"minutes are kept", utc(12, 17, 30), 1, utc(13, 9, 30)},
+		{"friday evening to monday", utc(16, 16, 0), 4, utc(19, 11, 0)},
+		{"at friday closing time", utc(16, 18, 0), 1, utc(19, 10, 0)},
+		{"from saturday", utc(17, 12, 0), 1, utc(19, 10, 0)},
+		{"from sunday night", utc(18, 23, 59), 2, utc(19, 11, 0)},
+		{"whole working week", utc(12, 9, 0), 45, utc(16, 18, 0)},
+		{"zero hours on weekend", utc(17, 12, 0), 0, utc(17, 12, 0)},
+	}
+
+	schedule := businesstime.Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schedule.AddHours(tt.from, tt.hours); !got.Equal(tt.want) {
+				t.Errorf("AddHours(%s, %d) = %s, want %s", tt.from, tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddHoursTimezone(t *testing.T) {
+	schedule, err := businesstime.Parse("09:00-18:00", "Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 05:00 UTC — 08:00 по Москве, до начала рабочего дня
+	from := time.Date(2026, time.October, 12, 5, 0, 0, 0, time.UTC)
+	got := schedule.AddHours(from, 1)
+	if want := time.Date(2026, time.October, 12, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got.Location() != time.UTC {
+		t.Errorf("result is in %s, want the location of the argument", got.Location())
+	}
+
+	// 16:00 UTC в пятницу — уже 19:00 по Москве, рабочий день закончился
+	from = time.Date(2026, time.October, 16, 16, 0, 0, 0, time.UTC)
+	if got, want := schedule.AddHours(from, 1), time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestAddHoursDaylightSaving(t *testing.T) {
+	schedule, err := businesstime.Parse("09:00-18:00", "America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// переход на летнее время 8 марта 2026: окно остаётся 09:00–18:00 по местным часам
+	from := time.Date(2026, time.March, 6, 17, 0, 0, 0, schedule.Location)
+	got := schedule.AddHours(from, 2)
+	if want := time.Date(2026, time.March, 9, 10, 0, 0, 0, schedule.Location); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	schedule, err := businesstime.Parse(" 09:30 - 17:45 ", "UTC")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if schedule.Start != 9*time.Hour+30*time.Minute || schedule.End != 17*time.Hour+45*time.Minute {
+		t.Errorf("got %s-%s", schedule.Start, schedule.End)
+	}
+
+	for _, tt := range []struct{ window, timezone string }{
+		{"18:00-09:00", "UTC"},
+		{"09:00-09:00", "UTC"},
+		{"9-18", "UTC"},
+		{"09:00", "UTC"},
+		{"09:00-18:00", "Mars/Olympus"},
+	} {
+		if _, err := businesstime.Parse(tt.window, tt.timezone); err == nil {
+			t.Errorf("Parse(%q, %q) succeeded, want error", tt.window, tt.timezone)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package hmacsig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const prefix = "sha256="
+
+// Sign возвращает подпись тела в формате "sha256=<hex>"
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify сравнивает подпись за постоянное время
+func Verify(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
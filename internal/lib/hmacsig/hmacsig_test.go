@@ -0,0 +1,44 @@
+package hmacsig_test
+
+import (
+	"testing"
+
+	"review-assignment/internal/lib/hmacsig"
+)
+
+func TestSign(t *testing.T) {
+	// общеизвестный пример HMAC-SHA256 с ключом "key"
+	got := hmacsig.Sign("key", []byte("The quick brown fox jumps over the lazy dog"))
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"CREATED","pull_request_id":"pr-1"}`)
+	signature := hmacsig.Sign("secret", body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"valid", "secret", body, signature, true},
+		{"tampered body", "secret", []byte(`{"event":"MERGED","pull_request_id":"pr-1"}`), signature, false},
+		{"wrong secret", "other", body, signature, false},
+		{"missing prefix", "secret", body, signature[len("sha256="):], false},
+		{"empty signature", "secret", body, "", false},
+		{"empty body", "secret", nil, hmacsig.Sign("secret", nil), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hmacsig.Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
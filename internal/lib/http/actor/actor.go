@@ -0,0 +1,20 @@
+package actor
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	Header    = "X-Actor"
+	Anonymous = "anonymous"
+)
+
+// FromRequest возвращает инициатора запроса для журнала аудита
+func FromRequest(c *gin.Context) string {
+	if name := strings.TrimSpace(c.GetHeader(Header)); name != "" {
+		return name
+	}
+	return Anonymous
+}
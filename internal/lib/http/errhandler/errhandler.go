@@ -0,0 +1,78 @@
+package errhandler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"review-assignment/internal/apperr"
+	"review-assignment/internal/lib/http/response"
+	"review-assignment/internal/lib/logger/sl"
+
+	"github.com/gin-gonic/gin"
+)
+
+var statuses = map[string]int{
+	apperr.CodeInvalidInput:       http.StatusBadRequest,
+	apperr.CodeTeamExists:         http.StatusBadRequest,
+	apperr.CodeNotFound:           http.StatusNotFound,
+	apperr.CodePRExists:           http.StatusConflict,
+	apperr.CodePRMerged:           http.StatusConflict,
+	apperr.CodePRNotOpen:          http.StatusConflict,
+	apperr.CodeNotAssigned:        http.StatusConflict,
+	apperr.CodeNoCandidate:        http.StatusConflict,
+	apperr.CodeNotEnoughReviewers: http.StatusConflict,
+	apperr.CodeNotEnoughApprovals: http.StatusConflict,
+	apperr.CodeInvalidTransition:  http.StatusConflict,
+	apperr.CodeTeamNotEmpty:       http.StatusConflict,
+	apperr.CodeMemberHasOpenPRs:   http.StatusConflict,
+	apperr.CodeConflict:           http.StatusConflict,
+	apperr.CodeDeliveryInProgress: http.StatusConflict,
+	apperr.CodeUnknownAccount:     http.StatusUnprocessableEntity,
+	apperr.CodeInvalidSignature:   http.StatusUnauthorized,
+	apperr.CodeNotConfigured:      http.StatusServiceUnavailable,
+	apperr.CodeTimeout:            http.StatusGatewayTimeout,
+	apperr.CodeCanceled:           statusClientClosedRequest,
+}
+
+// statusClientClosedRequest — нестандартный статус для запросов, прерванных клиентом (как в nginx)
+const statusClientClosedRequest = 499
+
+// Middleware превращает ошибку, переданную обработчиком через c.Error, в ответ ErrorResponse.
+// Неизвестные ошибки отдаются как INTERNAL_ERROR без подробностей, подробности попадают только в лог.
+func Middleware(log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		status, code, message := http.StatusInternalServerError, apperr.CodeInternal, "internal server error"
+		appErr := apperr.Lookup(err)
+		// драйвер сообщает об отмене запроса к БД своей ошибкой, причину берём из контекста запроса
+		if ctxErr := c.Request.Context().Err(); ctxErr != nil && (appErr == nil || appErr == apperr.ErrCanceled) {
+			appErr = apperr.Lookup(ctxErr)
+		}
+		if appErr != nil {
+			code, message = appErr.Code, appErr.Message
+			if s, ok := statuses[code]; ok {
+				status = s
+			}
+		}
+
+		attrs := []any{
+			sl.Err(err),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+		}
+		if status >= http.StatusInternalServerError {
+			log.Error("request failed", attrs...)
+		} else {
+			log.Warn("request rejected", attrs...)
+		}
+
+		c.JSON(status, response.NewErrorResponse(code, message))
+	}
+}
@@ -0,0 +1,31 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"review-assignment/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware ограничивает время обработки запроса: контекст запроса отменяется по истечении d
+// или при разрыве соединения клиентом, и вместе с ним прерываются запросы к БД.
+func Middleware(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		req := c.Request
+		c.Request = req.WithContext(ctx)
+		c.Next()
+		c.Request = req
+
+		// драйвер БД может вернуть свою ошибку отмены, поэтому причину фиксируем явно
+		if len(c.Errors) > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil {
+			c.Error(fmt.Errorf("%w: %w", apperr.ErrTimeout, c.Errors.Last().Err))
+		}
+	}
+}
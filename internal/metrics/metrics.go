@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "review_assignment"
+
+// Источники переназначений и отказов NO_CANDIDATE
+const (
+	SourceManual      = "manual"
+	SourceDeactivated = "user_deactivated"
+	SourceTeamChange  = "team_change"
+	SourceSLA         = "sla"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	PRsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prs_created_total",
+		Help:      "Pull requests created.",
+	})
+
+	PRsMerged = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prs_merged_total",
+		Help:      "Pull requests merged.",
+	})
+
+	Reassignments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewers_reassigned_total",
+		Help:      "Reviewers replaced on open pull requests, by source.",
+	}, []string{"source"})
+
+	NoCandidate = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_total",
+		Help:      "Reassignments that failed with NO_CANDIDATE, by source.",
+	}, []string{"source"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		PRsCreated, PRsMerged, Reassignments, NoCandidate,
+	)
+}
+
+// RegisterDB добавляет статистику пула соединений из sql.DB.Stats()
+func RegisterDB(db *sql.DB, name string) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterOpenReviews добавляет gauge открытых ревью по командам, вычисляемый при каждом scrape.
+// Запрос к БД при scrape ограничен timeout.
+func RegisterOpenReviews(count func(context.Context) (map[string]int, error), timeout time.Duration) {
+	registry.MustRegister(&openReviewsCollector{count: count, timeout: timeout})
+}
+
+// Middleware считает запросы и их длительность. Для неизвестных маршрутов route = "unmatched",
+// чтобы произвольные пути не раздували число временных рядов.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		httpDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+type openReviewsCollector struct {
+	count   func(context.Context) (map[string]int, error)
+	timeout time.Duration
+}
+
+var openReviewsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "open_reviews"),
+	"Review assignments on OPEN pull requests, by reviewer team.",
+	[]string{"team"}, nil,
+)
+
+func (c *openReviewsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openReviewsDesc
+}
+
+func (c *openReviewsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(openReviewsDesc, err)
+		return
+	}
+
+	for team, n := range counts {
+		ch <- prometheus.MustNewConstMetric(openReviewsDesc, prometheus.GaugeValue, float64(n), team)
+	}
+}
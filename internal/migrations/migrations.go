@@ -0,0 +1,439 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"review-assignment/internal/lib/logger/sl"
+)
+
+//go:embed sql/postgres/*.sql sql/sqlite/*.sql
+var files embed.FS
+
+// lockKey — ключ advisory lock, под которым миграции выполняет только одна реплика
+const lockKey int64 = 7310482615
+
+// dialect описывает различия служебных запросов мигратора между СУБД
+type dialect struct {
+	dir string
+	// lock и unlock пусты, если межпроцессная блокировка не нужна
+	lock           string
+	unlock         string
+	tableExists    string
+	createVersions string
+	// foreignKeysOff и foreignKeysOn выключают проверку внешних ключей на время
+	// миграций, а foreignKeyCheck перед коммитом ищет нарушенные ссылки
+	foreignKeysOff  string
+	foreignKeysOn   string
+	foreignKeyCheck string
+}
+
+var (
+	postgres = dialect{
+		dir:         "sql/postgres",
+		lock:        `SELECT pg_advisory_lock($1)`,
+		unlock:      `SELECT pg_advisory_unlock($1)`,
+		tableExists: `SELECT to_regclass('schema_migrations') IS NOT NULL`,
+		createVersions: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INTEGER PRIMARY KEY,
+				name VARCHAR(200) NOT NULL,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			)
+		`,
+	}
+	// в SQLite пишущие транзакции и так выполняются по одной, а версия
+	// перепроверяется внутри транзакции миграции.
+	// ALTER TABLE в SQLite не меняет ограничения, поэтому миграции пересоздают
+	// таблицу целиком; с включёнными внешними ключами DROP TABLE каскадно удалил бы
+	// ссылающиеся строки. Внутри транзакции PRAGMA foreign_keys не действует,
+	// поэтому проверка выключается на всё соединение мигратора.
+	sqlite = dialect{
+		dir:         "sql/sqlite",
+		tableExists: `SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`,
+		createVersions: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INTEGER PRIMARY KEY,
+				name TEXT NOT NULL,
+				applied_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+			)
+		`,
+		foreignKeysOff:  `PRAGMA foreign_keys = OFF`,
+		foreignKeysOn:   `PRAGMA foreign_keys = ON`,
+		foreignKeyCheck: `PRAGMA foreign_key_check`,
+	}
+)
+
+var (
+	ErrPending = errors.New("schema has pending migrations")
+	ErrUnknown = errors.New("schema has migrations unknown to this build")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status описывает состояние одной миграции; AppliedAt пуст, если миграция не применена
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db         *sql.DB
+	log        *slog.Logger
+	dialect    dialect
+	migrations []Migration
+}
+
+// New создаёт мигратор для PostgreSQL
+func New(db *sql.DB, log *slog.Logger) (*Migrator, error) {
+	const op = "migrations.New"
+
+	m, err := newMigrator(db, log, postgres)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return m, nil
+}
+
+// NewSQLite создаёт мигратор для SQLite
+func NewSQLite(db *sql.DB, log *slog.Logger) (*Migrator, error) {
+	const op = "migrations.NewSQLite"
+
+	m, err := newMigrator(db, log, sqlite)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return m, nil
+}
+
+func newMigrator(db *sql.DB, log *slog.Logger, d dialect) (*Migrator, error) {
+	migrations, err := load(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		log:        log,
+		dialect:    d,
+		migrations: migrations,
+	}, nil
+}
+
+// load читает встроенные файлы вида 0001_name.up.sql / 0001_name.down.sql из каталога dir
+func load(dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		body, err := files.ReadFile(dir + "/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up применяет все неприменённые миграции и возвращает их количество
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	const op = "migrations.Up"
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+
+			done := false
+			err := m.inTx(ctx, conn, func(tx *sql.Tx) error {
+				// без advisory lock миграцию могла применить другая реплика
+				if err := tx.QueryRowContext(ctx, `
+					SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)
+				`, mig.Version).Scan(&done); err != nil || done {
+					return err
+				}
+				if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `
+					INSERT INTO schema_migrations (version, name) VALUES ($1, $2)
+				`, mig.Version, mig.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("apply %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			if done {
+				continue
+			}
+
+			m.log.Info("migration applied", slog.Int("version", mig.Version), slog.String("name", mig.Name))
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// Down откатывает steps последних применённых миграций и возвращает их количество
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	const op = "migrations.Down"
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+
+			err := m.inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `
+					DELETE FROM schema_migrations WHERE version = $1
+				`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("revert %d_%s: %w", mig.Version, mig.Name, err)
+			}
+
+			m.log.Info("migration reverted", slog.Int("version", mig.Version), slog.String("name", mig.Name))
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// Status возвращает все известные миграции с отметкой о применении
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	const op = "migrations.Status"
+
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		result[i] = Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			result[i].AppliedAt = &at
+		}
+	}
+	return result, nil
+}
+
+// Check проверяет, что схема БД совпадает с миграциями этой сборки
+func (m *Migrator) Check(ctx context.Context) error {
+	const op = "migrations.Check"
+
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	known := make(map[int]bool, len(m.migrations))
+	pending := 0
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if _, ok := applied[mig.Version]; !ok {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%s: %w: %d", op, ErrPending, pending)
+	}
+	for version := range applied {
+		if !known[version] {
+			return fmt.Errorf("%s: %w: version %d", op, ErrUnknown, version)
+		}
+	}
+	return nil
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// applied возвращает версии применённых миграций; до первого запуска таблицы ещё нет
+func (m *Migrator) applied(ctx context.Context, q querier) (map[int]time.Time, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, m.dialect.tableExists).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]time.Time)
+	if !exists {
+		return result, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = appliedAt
+	}
+	return result, rows.Err()
+}
+
+// withLock выполняет fn на отдельном соединении под session-level advisory lock:
+// блокировка принадлежит соединению, поэтому все запросы идут через conn
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.lock, lockKey); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.WithoutCancel(ctx), m.dialect.unlock, lockKey); err != nil {
+				m.log.Error("failed to release migration lock", sl.Err(err))
+			}
+		}()
+	}
+
+	if m.dialect.foreignKeysOff != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.foreignKeysOff); err != nil {
+			return err
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.WithoutCancel(ctx), m.dialect.foreignKeysOn); err != nil {
+				m.log.Error("failed to restore foreign keys", sl.Err(err))
+			}
+		}()
+	}
+
+	_, err = conn.ExecContext(ctx, m.dialect.createVersions)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if m.dialect.foreignKeyCheck != "" {
+		if err := checkForeignKeys(ctx, tx, m.dialect.foreignKeyCheck); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// checkForeignKeys возвращает ошибку, если запрос check нашёл хотя бы одну нарушенную ссылку
+func checkForeignKeys(ctx context.Context, tx *sql.Tx, check string) error {
+	rows, err := tx.QueryContext(ctx, check)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table string
+		var rowID sql.NullInt64
+		var parent string
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return err
+		}
+		return fmt.Errorf("foreign key violation: %s row %d references missing %s", table, rowID.Int64, parent)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,196 @@
+-- Базовая схема сервиса.
+-- Все операции идемпотентны, поэтому миграция применяется и к БД, созданной до появления миграций.
+
+CREATE TABLE IF NOT EXISTS teams (
+    name VARCHAR(100) PRIMARY KEY,
+    assignment_strategy VARCHAR(20) NOT NULL DEFAULT 'random',
+    min_reviewers INTEGER NOT NULL DEFAULT 0,
+    max_reviewers INTEGER NOT NULL DEFAULT 2,
+    allow_cross_team BOOLEAN NOT NULL DEFAULT FALSE,
+    required_approvals INTEGER NOT NULL DEFAULT 0,
+    sla_hours INTEGER NOT NULL DEFAULT 0,
+    sla_action VARCHAR(20) NOT NULL DEFAULT 'escalate',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id VARCHAR(50) PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    team_name VARCHAR(100) NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    is_active BOOLEAN DEFAULT TRUE,
+    review_weight INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    pull_request_id VARCHAR(50) PRIMARY KEY,
+    pull_request_name VARCHAR(200) NOT NULL,
+    author_id VARCHAR(50) NOT NULL REFERENCES users(user_id),
+    status VARCHAR(20) DEFAULT 'OPEN',
+    assignment_strategy VARCHAR(20),
+    created_at TIMESTAMP DEFAULT NOW(),
+    merged_at TIMESTAMP NULL,
+    closed_at TIMESTAMP NULL
+);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pr_id VARCHAR(50) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id VARCHAR(50) REFERENCES users(user_id),
+    assigned_at TIMESTAMP DEFAULT NOW(),
+    from_fallback BOOLEAN NOT NULL DEFAULT FALSE,
+    state VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    state_updated_at TIMESTAMPTZ NULL,
+    escalated_at TIMESTAMPTZ NULL,
+    PRIMARY KEY (pr_id, reviewer_id)
+);
+
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name VARCHAR(100) REFERENCES teams(name) ON DELETE CASCADE,
+    fallback_team VARCHAR(100) REFERENCES teams(name) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team)
+);
+
+CREATE TABLE IF NOT EXISTS user_ooo (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(50) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE TABLE IF NOT EXISTS sla_events (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id VARCHAR(50) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id VARCHAR(50) NOT NULL REFERENCES users(user_id),
+    event_type VARCHAR(20) NOT NULL,
+    new_reviewer_id VARCHAR(50) NULL REFERENCES users(user_id),
+    reason TEXT NOT NULL DEFAULT '',
+    assigned_at TIMESTAMP NOT NULL,
+    deadline TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS pr_events (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id VARCHAR(50) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL,
+    reviewer_id VARCHAR(50) NULL,
+    old_reviewer_id VARCHAR(50) NULL,
+    new_reviewer_id VARCHAR(50) NULL,
+    state VARCHAR(20) NULL,
+    reason VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(100) NOT NULL,
+    operation VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    before JSONB NULL,
+    after JSONB NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '',
+    team_name VARCHAR(100) NULL REFERENCES teams(name) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE IF NOT EXISTS external_accounts (
+    provider VARCHAR(20) NOT NULL,
+    login VARCHAR(100) NOT NULL,
+    user_id VARCHAR(50) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, login)
+);
+
+CREATE TABLE IF NOT EXISTS integration_deliveries (
+    provider VARCHAR(20) NOT NULL,
+    delivery_id VARCHAR(100) NOT NULL,
+    event VARCHAR(50) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, delivery_id)
+);
+
+-- журнал аудита только дополняется: UPDATE и DELETE запрещены на уровне БД
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+-- колонки, добавленные в схему после первых релизов
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS assignment_strategy VARCHAR(20) NOT NULL DEFAULT 'random';
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS min_reviewers INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS max_reviewers INTEGER NOT NULL DEFAULT 2;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS allow_cross_team BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS review_weight INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS assignment_strategy VARCHAR(20);
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS from_fallback BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS state VARCHAR(20) NOT NULL DEFAULT 'PENDING';
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS state_updated_at TIMESTAMPTZ NULL;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS sla_hours INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS sla_action VARCHAR(20) NOT NULL DEFAULT 'escalate';
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ NULL;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name);
+CREATE INDEX IF NOT EXISTS idx_users_active ON users(team_name, is_active);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers ON pr_reviewers(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status ON pull_requests(status);
+CREATE INDEX IF NOT EXISTS idx_user_ooo_period ON user_ooo(user_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_sla_events_created ON sla_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_created ON pull_requests(created_at DESC, pull_request_id DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_author_created ON pull_requests(author_id, created_at DESC, pull_request_id DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_status_created ON pull_requests(status, created_at DESC, pull_request_id DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_merged ON pull_requests(merged_at);
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events(pr_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_log(actor, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_log(entity_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_created ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_pending ON webhook_outbox(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_external_accounts_user ON external_accounts(user_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_subscription ON webhook_outbox(subscription_id, id DESC);
+
+-- PR, созданные до появления pr_events, получают историю, восстановленную по текущему состоянию
+WITH missing AS (
+    SELECT pull_request_id, created_at, merged_at
+    FROM pull_requests pr
+    WHERE NOT EXISTS (SELECT 1 FROM pr_events e WHERE e.pr_id = pr.pull_request_id)
+)
+INSERT INTO pr_events (pr_id, event_type, reviewer_id, created_at)
+SELECT pull_request_id, 'CREATED', NULL, created_at FROM missing
+UNION ALL
+SELECT prr.pr_id, 'ASSIGNED', prr.reviewer_id, prr.assigned_at
+FROM pr_reviewers prr JOIN missing m ON m.pull_request_id = prr.pr_id
+UNION ALL
+SELECT pull_request_id, 'MERGED', NULL, merged_at FROM missing WHERE merged_at IS NOT NULL;
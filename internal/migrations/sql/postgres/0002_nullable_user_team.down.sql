@@ -0,0 +1,6 @@
+-- откат не пройдёт, пока есть пользователи без команды
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_team_name_fkey;
+ALTER TABLE users ADD CONSTRAINT users_team_name_fkey
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE;
+
+ALTER TABLE users ALTER COLUMN team_name SET NOT NULL;
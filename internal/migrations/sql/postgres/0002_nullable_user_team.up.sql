@@ -0,0 +1,8 @@
+-- Участник, удалённый из команды, остаётся в users без команды: на него ссылаются
+-- pull_requests, pr_reviewers и журналы. Команду с участниками удалить нельзя,
+-- вместо каскадного удаления пользователей.
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_team_name_fkey;
+ALTER TABLE users ADD CONSTRAINT users_team_name_fkey
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE RESTRICT;
@@ -0,0 +1,6 @@
+-- Merge Request Hook из GitLab сообщает об авторе MR только числовой author_id,
+-- поэтому связь с внешним аккаунтом может хранить и его id во внешней системе.
+ALTER TABLE external_accounts ADD COLUMN IF NOT EXISTS external_id VARCHAR(50) NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS external_accounts_provider_external_id_key
+    ON external_accounts(provider, external_id);
@@ -0,0 +1,5 @@
+-- незавершённые захваты не считаются обработанными доставками
+DELETE FROM integration_deliveries WHERE status <> 'DONE';
+
+ALTER TABLE integration_deliveries DROP COLUMN IF EXISTS lease_until;
+ALTER TABLE integration_deliveries DROP COLUMN IF EXISTS status;
@@ -0,0 +1,5 @@
+-- Входящая доставка сначала захватывается на время обработки (PROCESSING до lease_until)
+-- и отмечается обработанной (DONE) только после успешного изменения. Захват, оставшийся
+-- после падения процесса, истекает, и повторная доставка обрабатывается заново.
+ALTER TABLE integration_deliveries ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'DONE';
+ALTER TABLE integration_deliveries ADD COLUMN IF NOT EXISTS lease_until TIMESTAMPTZ NULL;
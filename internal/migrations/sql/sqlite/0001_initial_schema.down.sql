@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS integration_deliveries;
+DROP TABLE IF EXISTS external_accounts;
+DROP TABLE IF EXISTS webhook_outbox;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS pr_events;
+DROP TABLE IF EXISTS sla_events;
+DROP TABLE IF EXISTS user_ooo;
+DROP TABLE IF EXISTS team_fallbacks;
+DROP TABLE IF EXISTS pr_reviewers;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
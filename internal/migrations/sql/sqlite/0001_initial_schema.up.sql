@@ -0,0 +1,168 @@
+-- Базовая схема для SQLite, повторяет postgres/0001_initial_schema.up.sql.
+-- Время хранится текстом в UTC в формате '2006-01-02 15:04:05.999999999+00:00',
+-- поэтому строки сравниваются так же, как метки времени.
+
+CREATE TABLE IF NOT EXISTS teams (
+    name TEXT PRIMARY KEY,
+    assignment_strategy TEXT NOT NULL DEFAULT 'random',
+    min_reviewers INTEGER NOT NULL DEFAULT 0,
+    max_reviewers INTEGER NOT NULL DEFAULT 2,
+    allow_cross_team BOOLEAN NOT NULL DEFAULT FALSE,
+    required_approvals INTEGER NOT NULL DEFAULT 0,
+    sla_hours INTEGER NOT NULL DEFAULT 0,
+    sla_action TEXT NOT NULL DEFAULT 'escalate',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    is_active BOOLEAN DEFAULT TRUE,
+    review_weight INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT DEFAULT 'OPEN',
+    assignment_strategy TEXT,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    merged_at TIMESTAMP NULL,
+    closed_at TIMESTAMP NULL
+);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pr_id TEXT REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id TEXT REFERENCES users(user_id),
+    assigned_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    from_fallback BOOLEAN NOT NULL DEFAULT FALSE,
+    state TEXT NOT NULL DEFAULT 'PENDING',
+    state_updated_at TIMESTAMP NULL,
+    escalated_at TIMESTAMP NULL,
+    PRIMARY KEY (pr_id, reviewer_id)
+);
+
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name TEXT REFERENCES teams(name) ON DELETE CASCADE,
+    fallback_team TEXT REFERENCES teams(name) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team)
+);
+
+CREATE TABLE IF NOT EXISTS user_ooo (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE TABLE IF NOT EXISTS sla_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    event_type TEXT NOT NULL,
+    new_reviewer_id TEXT NULL REFERENCES users(user_id),
+    reason TEXT NOT NULL DEFAULT '',
+    assigned_at TIMESTAMP NOT NULL,
+    deadline TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS pr_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    reviewer_id TEXT NULL,
+    old_reviewer_id TEXT NULL,
+    new_reviewer_id TEXT NULL,
+    state TEXT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    before TEXT NULL,
+    after TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '',
+    team_name TEXT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    delivered_at TIMESTAMP NULL
+);
+
+CREATE TABLE IF NOT EXISTS external_accounts (
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (provider, login)
+);
+
+CREATE TABLE IF NOT EXISTS integration_deliveries (
+    provider TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (provider, delivery_id)
+);
+
+-- журнал аудита только дополняется: UPDATE и DELETE запрещены на уровне БД
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+    BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+    BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name);
+CREATE INDEX IF NOT EXISTS idx_users_active ON users(team_name, is_active);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers ON pr_reviewers(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_pr_status ON pull_requests(status);
+CREATE INDEX IF NOT EXISTS idx_user_ooo_period ON user_ooo(user_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_sla_events_created ON sla_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_created ON pull_requests(created_at DESC, pull_request_id DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_author_created ON pull_requests(author_id, created_at DESC, pull_request_id DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_status_created ON pull_requests(status, created_at DESC, pull_request_id DESC);
+CREATE INDEX IF NOT EXISTS idx_pr_merged ON pull_requests(merged_at);
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events(pr_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_log(actor, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_log(entity_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_created ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_pending ON webhook_outbox(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_external_accounts_user ON external_accounts(user_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_outbox_subscription ON webhook_outbox(subscription_id, id DESC);
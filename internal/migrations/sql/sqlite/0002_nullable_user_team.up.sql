@@ -0,0 +1,21 @@
+-- См. postgres/0002_nullable_user_team.up.sql. SQLite не умеет менять ограничения
+-- через ALTER TABLE, поэтому таблица users пересоздаётся (см. dialect sqlite в migrations.go).
+
+CREATE TABLE users_new (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_name TEXT NULL REFERENCES teams(name) ON DELETE RESTRICT,
+    is_active BOOLEAN DEFAULT TRUE,
+    review_weight INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+INSERT INTO users_new (user_id, username, team_name, is_active, review_weight, created_at, updated_at)
+SELECT user_id, username, team_name, is_active, review_weight, created_at, updated_at FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name);
+CREATE INDEX IF NOT EXISTS idx_users_active ON users(team_name, is_active);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS external_accounts_provider_external_id_key;
+
+ALTER TABLE external_accounts DROP COLUMN external_id;
@@ -0,0 +1,5 @@
+-- См. postgres/0003_external_account_id.up.sql
+ALTER TABLE external_accounts ADD COLUMN external_id TEXT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS external_accounts_provider_external_id_key
+    ON external_accounts(provider, external_id);
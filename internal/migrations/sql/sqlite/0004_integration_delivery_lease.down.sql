@@ -0,0 +1,4 @@
+DELETE FROM integration_deliveries WHERE status <> 'DONE';
+
+ALTER TABLE integration_deliveries DROP COLUMN lease_until;
+ALTER TABLE integration_deliveries DROP COLUMN status;
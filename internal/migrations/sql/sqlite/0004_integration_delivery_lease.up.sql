@@ -0,0 +1,3 @@
+-- См. postgres/0004_integration_delivery_lease.up.sql
+ALTER TABLE integration_deliveries ADD COLUMN status TEXT NOT NULL DEFAULT 'DONE';
+ALTER TABLE integration_deliveries ADD COLUMN lease_until TIMESTAMP NULL;
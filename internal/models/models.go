@@ -1,12 +1,107 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type PRStatus string
 
 const (
 	StatusOpen   PRStatus = "OPEN"
 	StatusMerged PRStatus = "MERGED"
+	StatusDraft  PRStatus = "DRAFT"
+	StatusClosed PRStatus = "CLOSED"
+)
+
+type ReviewState string
+
+const (
+	ReviewPending          ReviewState = "PENDING"
+	ReviewApproved         ReviewState = "APPROVED"
+	ReviewChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewDismissed        ReviewState = "DISMISSED"
+)
+
+type SLAAction string
+
+const (
+	SLAActionEscalate SLAAction = "escalate"
+	SLAActionReassign SLAAction = "reassign"
+)
+
+type SLAEventType string
+
+const (
+	SLAEventEscalated  SLAEventType = "ESCALATED"
+	SLAEventReassigned SLAEventType = "REASSIGNED"
+)
+
+type PREventType string
+
+const (
+	PREventCreated    PREventType = "CREATED"
+	PREventAssigned   PREventType = "ASSIGNED"
+	PREventReassigned PREventType = "REASSIGNED"
+	PREventReviewed   PREventType = "REVIEWED"
+	PREventReady      PREventType = "READY"
+	PREventClosed     PREventType = "CLOSED"
+	PREventReopened   PREventType = "REOPENED"
+	PREventMerged     PREventType = "MERGED"
+	PREventUnassigned PREventType = "UNASSIGNED"
+)
+
+const (
+	ReasonManual          = "MANUAL"
+	ReasonUserDeactivated = "USER_DEACTIVATED"
+	ReasonMemberRemoved   = "MEMBER_REMOVED"
+	ReasonMemberMoved     = "MEMBER_MOVED"
+	ReasonSLA             = "SLA"
+	ReasonPRClosed        = "PR_CLOSED"
+)
+
+type AuditOperation string
+
+const (
+	AuditCreateTeam           AuditOperation = "CREATE_TEAM"
+	AuditSetTeamPolicy        AuditOperation = "SET_TEAM_POLICY"
+	AuditDeactivateTeamUsers  AuditOperation = "DEACTIVATE_TEAM_USERS"
+	AuditSetUserActive        AuditOperation = "SET_USER_ACTIVE"
+	AuditCreatePR             AuditOperation = "CREATE_PR"
+	AuditMergePR              AuditOperation = "MERGE_PR"
+	AuditMarkReady            AuditOperation = "MARK_READY"
+	AuditClosePR              AuditOperation = "CLOSE_PR"
+	AuditReopenPR             AuditOperation = "REOPEN_PR"
+	AuditReassignReviewer     AuditOperation = "REASSIGN_REVIEWER"
+	AuditSubmitReview         AuditOperation = "SUBMIT_REVIEW"
+	AuditProcessOverdueReview AuditOperation = "PROCESS_OVERDUE_REVIEW"
+	AuditRemoveTeamMember     AuditOperation = "REMOVE_TEAM_MEMBER"
+	AuditMoveTeamMember       AuditOperation = "MOVE_TEAM_MEMBER"
+	AuditDeleteTeam           AuditOperation = "DELETE_TEAM"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "PENDING"
+	DeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	DeliveryDead      WebhookDeliveryStatus = "DEAD"
+)
+
+type IntegrationProvider string
+
+const (
+	ProviderGitHub IntegrationProvider = "github"
+	ProviderGitLab IntegrationProvider = "gitlab"
+)
+
+type AssignmentStrategy string
+
+const (
+	StrategyRandom      AssignmentStrategy = "random"
+	StrategyRoundRobin  AssignmentStrategy = "round_robin"
+	StrategyWeighted    AssignmentStrategy = "weighted"
+	StrategyLeastLoaded AssignmentStrategy = "least_loaded"
 )
 
 type User struct {
@@ -14,21 +109,38 @@ type User struct {
 	Username string `json:"username"`
 	TeamName string `json:"team_name,omitempty"`
 	IsActive bool   `json:"is_active"`
+	Weight   *int   `json:"review_weight,omitempty"`
 }
 
 type Team struct {
-	Name    string `json:"team_name"`
-	Members []User `json:"members"`
+	Name     string             `json:"team_name"`
+	Strategy AssignmentStrategy `json:"assignment_strategy,omitempty"`
+	Members  []User             `json:"members"`
+}
+
+type TeamPolicy struct {
+	TeamName          string             `json:"team_name"`
+	MinReviewers      int                `json:"min_reviewers"`
+	MaxReviewers      int                `json:"max_reviewers"`
+	Strategy          AssignmentStrategy `json:"assignment_strategy"`
+	AllowCrossTeam    bool               `json:"allow_cross_team"`
+	FallbackTeams     []string           `json:"fallback_teams"`
+	RequiredApprovals int                `json:"required_approvals"`
+	SLAHours          int                `json:"sla_hours"`
+	SLAAction         SLAAction          `json:"sla_action"`
 }
 
 type PullRequest struct {
-	ID                string     `json:"pull_request_id"`
-	Name              string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            PRStatus   `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         time.Time  `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	ID                string             `json:"pull_request_id"`
+	Name              string             `json:"pull_request_name"`
+	AuthorID          string             `json:"author_id"`
+	Status            PRStatus           `json:"status"`
+	AssignedReviewers []string           `json:"assigned_reviewers"`
+	FallbackReviewers []string           `json:"fallback_reviewers,omitempty"`
+	Strategy          AssignmentStrategy `json:"assignment_strategy,omitempty"`
+	CreatedAt         time.Time          `json:"createdAt,omitempty"`
+	MergedAt          *time.Time         `json:"mergedAt,omitempty"`
+	ClosedAt          *time.Time         `json:"closedAt,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -39,17 +151,286 @@ type PullRequestShort struct {
 }
 
 type CreateTeamRequest struct {
-	Name    string `json:"team_name"`
-	Members []User `json:"members"`
+	Name     string             `json:"team_name"`
+	Strategy AssignmentStrategy `json:"assignment_strategy"`
+	Members  []User             `json:"members"`
 }
 
 type CreatePRRequest struct {
 	ID       string `json:"pull_request_id"`
 	Name     string `json:"pull_request_name"`
 	AuthorID string `json:"author_id"`
+	Draft    bool   `json:"draft"`
 }
 
 type ReassignRequest struct {
 	PRID        string `json:"pull_request_id"`
 	OldReviewer string `json:"old_reviewer_id"`
 }
+
+type SetTeamPolicyRequest struct {
+	TeamName          string              `json:"team_name" binding:"required"`
+	MinReviewers      *int                `json:"min_reviewers"`
+	MaxReviewers      *int                `json:"max_reviewers"`
+	Strategy          *AssignmentStrategy `json:"assignment_strategy"`
+	AllowCrossTeam    *bool               `json:"allow_cross_team"`
+	FallbackTeams     *[]string           `json:"fallback_teams"`
+	RequiredApprovals *int                `json:"required_approvals"`
+	SLAHours          *int                `json:"sla_hours"`
+	SLAAction         *SLAAction          `json:"sla_action"`
+}
+
+type Reassignment struct {
+	PRID        string `json:"pull_request_id"`
+	OldReviewer string `json:"old_reviewer_id"`
+	NewReviewer string `json:"replaced_by"`
+}
+
+type ReassignmentFailure struct {
+	PRID        string `json:"pull_request_id"`
+	OldReviewer string `json:"old_reviewer_id"`
+	Reason      string `json:"reason"`
+}
+
+type ReassignmentReport struct {
+	Reassigned    []Reassignment        `json:"reassigned"`
+	NotReassigned []ReassignmentFailure `json:"not_reassigned"`
+}
+
+type TeamDeactivationRequest struct {
+	TeamName string   `json:"team_name" binding:"required"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type TeamDeactivationResult struct {
+	TeamName    string   `json:"team_name"`
+	Deactivated []string `json:"deactivated"`
+	ReassignmentReport
+}
+
+// OpenReviewsAction — что делать с открытыми ревью участника при переводе в другую команду
+type OpenReviewsAction string
+
+const (
+	OpenReviewsKeep     OpenReviewsAction = "keep"
+	OpenReviewsReassign OpenReviewsAction = "reassign"
+)
+
+type RemoveTeamMemberRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+	UserID   string `json:"user_id" binding:"required"`
+}
+
+type MoveTeamMemberRequest struct {
+	UserID      string            `json:"user_id" binding:"required"`
+	ToTeam      string            `json:"to_team" binding:"required"`
+	OpenReviews OpenReviewsAction `json:"open_reviews" binding:"required"`
+}
+
+type DeleteTeamRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+}
+
+// TeamMemberChange — результат удаления участника из команды или перевода в другую.
+// У удалённого участника team_name пуст, а is_active = false.
+type TeamMemberChange struct {
+	User     User   `json:"user"`
+	FromTeam string `json:"from_team"`
+	ReassignmentReport
+}
+
+type OutOfOffice struct {
+	ID       int64     `json:"ooo_id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+type AddOutOfOfficeRequest struct {
+	UserID   string    `json:"user_id" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Reason   string    `json:"reason"`
+}
+
+type RemoveOutOfOfficeRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	ID     int64  `json:"ooo_id" binding:"required"`
+}
+
+type Review struct {
+	PRID       string      `json:"pull_request_id"`
+	ReviewerID string      `json:"reviewer_id"`
+	State      ReviewState `json:"state"`
+	UpdatedAt  *time.Time  `json:"updated_at,omitempty"`
+}
+
+type SubmitReviewRequest struct {
+	PRID       string      `json:"pull_request_id" binding:"required"`
+	ReviewerID string      `json:"reviewer_id" binding:"required"`
+	State      ReviewState `json:"state" binding:"required"`
+}
+
+type OverdueReview struct {
+	PRID       string    `json:"pull_request_id"`
+	ReviewerID string    `json:"reviewer_id"`
+	TeamName   string    `json:"team_name"`
+	AssignedAt time.Time `json:"assigned_at"`
+	Deadline   time.Time `json:"deadline"`
+	Escalated  bool      `json:"escalated"`
+}
+
+type SLAEvent struct {
+	ID            int64        `json:"event_id"`
+	PRID          string       `json:"pull_request_id"`
+	ReviewerID    string       `json:"reviewer_id"`
+	Type          SLAEventType `json:"type"`
+	NewReviewerID string       `json:"new_reviewer_id,omitempty"`
+	Reason        string       `json:"reason,omitempty"`
+	AssignedAt    time.Time    `json:"assigned_at"`
+	Deadline      time.Time    `json:"deadline"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+type PRListFilter struct {
+	Statuses     []PRStatus
+	AuthorID     string
+	ReviewerID   string
+	TeamName     string
+	NameContains string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	MergedFrom   *time.Time
+	MergedTo     *time.Time
+	Limit        int
+	Cursor       string
+}
+
+type PRListPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+type PREvent struct {
+	ID            int64       `json:"event_id"`
+	PRID          string      `json:"pull_request_id"`
+	Type          PREventType `json:"type"`
+	ReviewerID    string      `json:"reviewer_id,omitempty"`
+	OldReviewerID string      `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string      `json:"new_reviewer_id,omitempty"`
+	State         ReviewState `json:"state,omitempty"`
+	Reason        string      `json:"reason,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+type PRDetails struct {
+	PR       *PullRequest `json:"pr"`
+	Reviews  []Review     `json:"reviews"`
+	Timeline []PREvent    `json:"timeline"`
+}
+
+type AuditEntry struct {
+	ID        int64           `json:"audit_id"`
+	Actor     string          `json:"actor"`
+	Operation AuditOperation  `json:"operation"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type AuditFilter struct {
+	Actor      string
+	Operations []AuditOperation
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Cursor     string
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type WebhookSubscription struct {
+	ID         int64         `json:"subscription_id"`
+	URL        string        `json:"url"`
+	Secret     string        `json:"-"`
+	EventTypes []PREventType `json:"event_types"`
+	TeamName   string        `json:"team_name,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type AddWebhookRequest struct {
+	URL        string        `json:"url" binding:"required"`
+	Secret     string        `json:"secret" binding:"required"`
+	EventTypes []PREventType `json:"event_types"`
+	TeamName   string        `json:"team_name"`
+}
+
+type WebhookIDRequest struct {
+	ID int64 `json:"subscription_id" binding:"required"`
+}
+
+type RedeliverWebhookRequest struct {
+	ID int64 `json:"delivery_id" binding:"required"`
+}
+
+// WebhookEvent — тело запроса, отправляемого подписчику
+type WebhookEvent struct {
+	Type          PREventType `json:"event"`
+	TeamName      string      `json:"team_name"`
+	PRID          string      `json:"pull_request_id"`
+	ReviewerID    string      `json:"reviewer_id,omitempty"`
+	OldReviewerID string      `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string      `json:"new_reviewer_id,omitempty"`
+	State         ReviewState `json:"state,omitempty"`
+	Reason        string      `json:"reason,omitempty"`
+	OccurredAt    time.Time   `json:"occurred_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64                 `json:"delivery_id"`
+	SubscriptionID int64                 `json:"subscription_id"`
+	EventType      PREventType           `json:"event"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	LastError      string                `json:"last_error,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+
+	// заполняются только при выдаче диспетчеру
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+type ExternalAccount struct {
+	Provider   IntegrationProvider `json:"provider"`
+	Login      string              `json:"login"`
+	ExternalID string              `json:"external_id,omitempty"`
+	UserID     string              `json:"user_id"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+type SetExternalAccountRequest struct {
+	Provider IntegrationProvider `json:"provider" binding:"required"`
+	Login    string              `json:"login" binding:"required"`
+	// ExternalID — числовой id пользователя во внешней системе, нужен для GitLab
+	ExternalID string `json:"external_id"`
+	UserID     string `json:"user_id" binding:"required"`
+}
+
+type RemoveExternalAccountRequest struct {
+	Provider IntegrationProvider `json:"provider" binding:"required"`
+	Login    string              `json:"login" binding:"required"`
+}
+
+type ExternalReviewer struct {
+	UserID string `json:"user_id"`
+	Login  string `json:"login,omitempty"`
+}
@@ -0,0 +1,170 @@
+package selector
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"review-assignment/internal/models"
+)
+
+var ErrUnknownStrategy = errors.New("UNKNOWN_STRATEGY")
+
+// Candidate описывает пользователя, которого можно назначить ревьювером
+type Candidate struct {
+	UserID         string
+	Weight         int
+	OpenReviews    int
+	LastAssignedAt *time.Time
+}
+
+// Selector выбирает до n ревьюверов из списка кандидатов
+type Selector interface {
+	Select(candidates []Candidate, n int) []string
+}
+
+// Rand — источник случайности для стратегий. Один экземпляр используется
+// конкурентными запросами, поэтому реализация должна быть потокобезопасной.
+type Rand interface {
+	Shuffle(n int, swap func(i, j int))
+	Intn(n int) int
+}
+
+// SafeRand возвращает Rand на функциях верхнего уровня math/rand/v2, безопасных для конкурентного вызова
+func SafeRand() Rand {
+	return safeRand{}
+}
+
+type safeRand struct{}
+
+func (safeRand) Shuffle(n int, swap func(i, j int)) { rand.Shuffle(n, swap) }
+func (safeRand) Intn(n int) int                     { return rand.IntN(n) }
+
+// New возвращает реализацию стратегии выбора ревьюверов
+func New(strategy models.AssignmentStrategy, rng Rand) (Selector, error) {
+	switch strategy {
+	case models.StrategyRandom, "":
+		return &randomSelector{rng: rng}, nil
+	case models.StrategyRoundRobin:
+		return &roundRobinSelector{}, nil
+	case models.StrategyWeighted:
+		return &weightedSelector{rng: rng}, nil
+	case models.StrategyLeastLoaded:
+		return &leastLoadedSelector{rng: rng}, nil
+	default:
+		return nil, ErrUnknownStrategy
+	}
+}
+
+// Valid проверяет, что стратегия поддерживается
+func Valid(strategy models.AssignmentStrategy) bool {
+	_, err := New(strategy, nil)
+	return err == nil
+}
+
+type randomSelector struct {
+	rng Rand
+}
+
+func (s *randomSelector) Select(candidates []Candidate, n int) []string {
+	shuffled := make([]Candidate, len(candidates))
+	copy(shuffled, candidates)
+
+	s.rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return take(shuffled, n)
+}
+
+// roundRobinSelector отдает предпочтение тем, кого дольше всех не назначали
+type roundRobinSelector struct{}
+
+func (s *roundRobinSelector) Select(candidates []Candidate, n int) []string {
+	sorted := make([]Candidate, len(candidates))
+	copy(sorted, candidates)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].LastAssignedAt, sorted[j].LastAssignedAt
+		switch {
+		case a == nil && b == nil:
+			return sorted[i].UserID < sorted[j].UserID
+		case a == nil:
+			return true
+		case b == nil:
+			return false
+		case a.Equal(*b):
+			return sorted[i].UserID < sorted[j].UserID
+		default:
+			return a.Before(*b)
+		}
+	})
+
+	return take(sorted, n)
+}
+
+// leastLoadedSelector выбирает тех, у кого меньше всего открытых ревью.
+// При равной нагрузке порядок случайный.
+type leastLoadedSelector struct {
+	rng Rand
+}
+
+func (s *leastLoadedSelector) Select(candidates []Candidate, n int) []string {
+	sorted := make([]Candidate, len(candidates))
+	copy(sorted, candidates)
+
+	s.rng.Shuffle(len(sorted), func(i, j int) {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	})
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OpenReviews < sorted[j].OpenReviews
+	})
+
+	return take(sorted, n)
+}
+
+// weightedSelector выбирает без повторений с вероятностью, пропорциональной весу.
+// Кандидаты с нулевым весом не назначаются.
+type weightedSelector struct {
+	rng Rand
+}
+
+func (s *weightedSelector) Select(candidates []Candidate, n int) []string {
+	pool := make([]Candidate, 0, len(candidates))
+	total := 0
+	for _, c := range candidates {
+		if c.Weight > 0 {
+			pool = append(pool, c)
+			total += c.Weight
+		}
+	}
+
+	result := []string{}
+	for len(result) < n && len(pool) > 0 {
+		point := s.rng.Intn(total)
+		for i, c := range pool {
+			if point < c.Weight {
+				result = append(result, c.UserID)
+				total -= c.Weight
+				pool = append(pool[:i], pool[i+1:]...)
+				break
+			}
+			point -= c.Weight
+		}
+	}
+
+	return result
+}
+
+func take(candidates []Candidate, n int) []string {
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+
+	result := make([]string, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.UserID
+	}
+	return result
+}
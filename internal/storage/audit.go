@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"review-assignment/internal/models"
+)
+
+// AUDIT METHODS
+
+// ListAudit возвращает записи журнала аудита от новых к старым.
+// Курсор — идентификатор последней записи предыдущей страницы.
+func (s *Storage) ListAudit(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error) {
+	const op = "storage.ListAudit"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	entries := []models.AuditEntry{}
+	err := s.queryAudit(ctx, filter, limit+1, func(entry models.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := &models.AuditPage{}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = strconv.FormatInt(entries[len(entries)-1].ID, 10)
+	}
+	page.Entries = entries
+
+	return page, nil
+}
+
+// ExportAudit построчно отдаёт в fn все записи, подходящие под фильтр, без загрузки их в память
+func (s *Storage) ExportAudit(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error {
+	const op = "storage.ExportAudit"
+
+	if err := s.queryAudit(ctx, filter, 0, fn); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) queryAudit(ctx context.Context, filter models.AuditFilter, limit int, fn func(models.AuditEntry) error) error {
+	var conditions []string
+	var params []interface{}
+	arg := func(v interface{}) string {
+		params = append(params, v)
+		return fmt.Sprintf("$%d", len(params))
+	}
+
+	if filter.Cursor != "" {
+		id, err := strconv.ParseInt(filter.Cursor, 10, 64)
+		if err != nil {
+			return ErrInvalidCursor
+		}
+		conditions = append(conditions, "id < "+arg(id))
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+arg(filter.Actor))
+	}
+	if len(filter.Operations) > 0 {
+		operations := make([]string, len(filter.Operations))
+		for i, operation := range filter.Operations {
+			operations[i] = arg(operation)
+		}
+		conditions = append(conditions, "operation IN ("+strings.Join(operations, ", ")+")")
+	}
+	if filter.EntityID != "" {
+		conditions = append(conditions, "entity_id = "+arg(filter.EntityID))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.To))
+	}
+
+	query := `
+		SELECT id, actor, operation, entity_id, before, after, created_at
+		FROM audit_log
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if limit > 0 {
+		query += " LIMIT " + arg(limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		var operation string
+		var before, after []byte
+
+		if err := rows.Scan(&entry.ID, &entry.Actor, &operation, &entry.EntityID,
+			&before, &after, &entry.CreatedAt); err != nil {
+			return err
+		}
+
+		entry.Operation = models.AuditOperation(operation)
+		entry.Before = before
+		entry.After = after
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// recordAudit дописывает запись в журнал аудита в рамках переданной транзакции.
+// before и after сериализуются в JSON, nil сохраняется как NULL.
+func (s *Storage) recordAudit(ctx context.Context, q querier, actor string, operation models.AuditOperation, entityID string, before, after interface{}) error {
+	beforeJSON, err := auditPayload(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditPayload(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO audit_log (actor, operation, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5)
+	`, actor, operation, entityID, beforeJSON, afterJSON)
+	return err
+}
+
+func auditPayload(v interface{}) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
@@ -0,0 +1,94 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// TestAuditCoversChanges проверяет, что изменения политики, жизненного цикла PR,
+// ревью, деактивация и обработка SLA попадают в журнал аудита со своим инициатором
+func TestAuditCoversChanges(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+
+			members := createTeam(t, repo, "core", 4)
+			slaHours := 1
+			if _, err := repo.SetTeamPolicy(ctx, models.SetTeamPolicyRequest{TeamName: "core", SLAHours: &slaHours}, "alice"); err != nil {
+				t.Fatalf("set policy: %v", err)
+			}
+
+			_, err := repo.CreatePR(ctx, models.CreatePRRequest{ID: "pr-1", Name: "x", AuthorID: members[0], Draft: true}, "alice")
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			pr, err := repo.MarkReady(ctx, "pr-1", "alice")
+			if err != nil {
+				t.Fatalf("ready: %v", err)
+			}
+			if len(pr.AssignedReviewers) == 0 {
+				t.Fatal("ready PR has no reviewers")
+			}
+			review := models.SubmitReviewRequest{PRID: "pr-1", ReviewerID: pr.AssignedReviewers[0], State: models.ReviewApproved}
+			if _, err := repo.SubmitReview(ctx, review, "alice"); err != nil {
+				t.Fatalf("review: %v", err)
+			}
+			if _, err := repo.ClosePR(ctx, "pr-1", "alice"); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+			if _, err := repo.ReopenPR(ctx, "pr-1", "alice"); err != nil {
+				t.Fatalf("reopen: %v", err)
+			}
+
+			events, err := repo.ProcessOverdueReviews(ctx, time.Now().AddDate(0, 1, 0), "system:sla")
+			if err != nil || len(events) == 0 {
+				t.Fatalf("process overdue: %d events, %v", len(events), err)
+			}
+
+			if _, err := repo.DeactivateTeamUsers(ctx, "core", members[3:], "alice"); err != nil {
+				t.Fatalf("deactivate: %v", err)
+			}
+
+			for _, want := range []struct {
+				operation models.AuditOperation
+				actor     string
+			}{
+				{models.AuditSetTeamPolicy, "alice"},
+				{models.AuditMarkReady, "alice"},
+				{models.AuditSubmitReview, "alice"},
+				{models.AuditClosePR, "alice"},
+				{models.AuditReopenPR, "alice"},
+				{models.AuditProcessOverdueReview, "system:sla"},
+				{models.AuditDeactivateTeamUsers, "alice"},
+			} {
+				checkAudited(t, repo, want.operation, want.actor)
+			}
+		})
+	}
+}
+
+func checkAudited(t *testing.T, repo storage.Repository, operation models.AuditOperation, actor string) {
+	t.Helper()
+
+	page, err := repo.ListAudit(context.Background(), models.AuditFilter{Operations: []models.AuditOperation{operation}})
+	if err != nil {
+		t.Fatalf("list audit %s: %v", operation, err)
+	}
+	if len(page.Entries) == 0 {
+		t.Errorf("no audit entry for %s", operation)
+		return
+	}
+	for _, entry := range page.Entries {
+		if entry.Actor != actor {
+			t.Errorf("%s recorded with actor %q, want %q", operation, entry.Actor, actor)
+		}
+		if len(entry.After) == 0 {
+			t.Errorf("%s recorded without after state", operation)
+		}
+	}
+}
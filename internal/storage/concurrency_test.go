@@ -0,0 +1,156 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// expectedUnderLoad — ошибки, которые законно возникают при гонке операций:
+// кандидатов не осталось, ревьювера уже заменили, PR ещё не создан и т. п.
+var expectedUnderLoad = []error{
+	storage.ErrNoCandidate,
+	storage.ErrNotAssigned,
+	storage.ErrNotEnoughReviewers,
+	storage.ErrConcurrentUpdate,
+	storage.ErrPRNotFound,
+}
+
+func checkUnderLoad(t *testing.T, what string, err error) {
+	t.Helper()
+
+	if err == nil {
+		return
+	}
+	for _, expected := range expectedUnderLoad {
+		if errors.Is(err, expected) {
+			return
+		}
+	}
+	t.Errorf("%s: unexpected error: %v", what, err)
+}
+
+// TestConcurrentAssignment параллельно создаёт PR, переназначает ревьюверов и
+// переключает активность пользователей, после чего проверяет инварианты на каждом PR.
+// Запускать с -race; блокировки строк проверяются только на PostgreSQL (TEST_POSTGRES_DSN).
+func TestConcurrentAssignment(t *testing.T) {
+	const (
+		creators    = 4
+		prsPerGo    = 25
+		reassigners = 4
+		togglers    = 2
+		iterations  = 60
+	)
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+
+			members := createTeam(t, repo, "core", 6)
+			createTeam(t, repo, "spare", 3)
+			allowCrossTeam, fallbacks := true, []string{"spare"}
+			_, err := repo.SetTeamPolicy(ctx, models.SetTeamPolicyRequest{
+				TeamName:       "core",
+				AllowCrossTeam: &allowCrossTeam,
+				FallbackTeams:  &fallbacks,
+			}, "test")
+			if err != nil {
+				t.Fatalf("set policy: %v", err)
+			}
+
+			var (
+				mu      sync.Mutex
+				created []string
+			)
+			randomPR := func() (string, bool) {
+				mu.Lock()
+				defer mu.Unlock()
+				if len(created) == 0 {
+					return "", false
+				}
+				return created[rand.IntN(len(created))], true
+			}
+
+			var wg sync.WaitGroup
+			for g := range creators {
+				wg.Go(func() {
+					for i := range prsPerGo {
+						req := models.CreatePRRequest{
+							ID:       fmt.Sprintf("pr-%d-%d", g, i),
+							Name:     "stress",
+							AuthorID: members[rand.IntN(len(members))],
+						}
+						pr, err := repo.CreatePR(ctx, req, "test")
+						checkUnderLoad(t, "create "+req.ID, err)
+						if err != nil {
+							continue
+						}
+						checkReviewers(t, pr)
+
+						mu.Lock()
+						created = append(created, pr.ID)
+						mu.Unlock()
+					}
+				})
+			}
+
+			for range reassigners {
+				wg.Go(func() {
+					for range iterations {
+						prID, ok := randomPR()
+						if !ok {
+							continue
+						}
+						details, err := repo.GetPR(ctx, prID)
+						if err != nil {
+							t.Errorf("get %s: %v", prID, err)
+							continue
+						}
+						if len(details.PR.AssignedReviewers) == 0 {
+							continue
+						}
+
+						old := details.PR.AssignedReviewers[rand.IntN(len(details.PR.AssignedReviewers))]
+						pr, _, err := repo.ReassignReviewer(ctx, models.ReassignRequest{PRID: prID, OldReviewer: old}, "test")
+						checkUnderLoad(t, "reassign "+prID, err)
+						if err == nil {
+							checkReviewers(t, pr)
+						}
+					}
+				})
+			}
+
+			for range togglers {
+				wg.Go(func() {
+					for range iterations / 2 {
+						userID := members[rand.IntN(len(members))]
+						_, _, err := repo.SetUserActive(ctx, userID, false, "test")
+						checkUnderLoad(t, "deactivate "+userID, err)
+						_, _, err = repo.SetUserActive(ctx, userID, true, "test")
+						checkUnderLoad(t, "activate "+userID, err)
+					}
+				})
+			}
+
+			wg.Wait()
+
+			if len(created) != creators*prsPerGo {
+				t.Errorf("created %d PRs, want %d", len(created), creators*prsPerGo)
+			}
+			for _, prID := range created {
+				details, err := repo.GetPR(ctx, prID)
+				if err != nil {
+					t.Fatalf("get %s: %v", prID, err)
+				}
+				checkReviewers(t, details.PR)
+			}
+		})
+	}
+}
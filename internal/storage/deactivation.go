@@ -0,0 +1,486 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/models"
+	"review-assignment/internal/selector"
+)
+
+// batchSize ограничивает число строк в одном bulk-запросе. Кроме лимита параметров
+// важна стоимость их привязки: драйвер SQLite ищет каждый параметр перебором
+// всех аргументов, поэтому запрос на тысячи параметров привязывается квадратично долго.
+const batchSize = 100
+
+type openAssignment struct {
+	prID         string
+	reviewerID   string
+	reviewerTeam string
+	authorID     string
+	authorTeam   string
+}
+
+type reviewerChange struct {
+	prID         string
+	oldReviewer  string
+	newReviewer  string
+	fromFallback bool
+	strategy     models.AssignmentStrategy
+	reason       string
+}
+
+func (s *Storage) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string, actor string) (*models.TeamDeactivationResult, error) {
+	const op = "storage.DeactivateTeamUsers"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var teamExists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)
+	`, teamName).Scan(&teamExists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !teamExists {
+		return nil, fmt.Errorf("%s: %w", op, ErrTeamNotFound)
+	}
+
+	where := "team_name = $1"
+	params := []interface{}{teamName}
+	userIDs = unique(userIDs)
+	if len(userIDs) > 0 {
+		where += " AND user_id IN (" + placeholders(2, len(userIDs)) + ")"
+		params = append(params, stringArgs(userIDs)...)
+	}
+
+	before, err := s.lockUsers(ctx, tx, where, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE users
+		SET is_active = false, updated_at = NOW()
+		WHERE `+where+`
+		RETURNING user_id
+	`, params...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	deactivated, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(userIDs) > 0 && len(deactivated) != len(userIDs) {
+		return nil, fmt.Errorf("%s: %w", op, ErrMemberNotFound)
+	}
+
+	result := &models.TeamDeactivationResult{
+		TeamName:    teamName,
+		Deactivated: deactivated,
+		ReassignmentReport: models.ReassignmentReport{
+			Reassigned:    []models.Reassignment{},
+			NotReassigned: []models.ReassignmentFailure{},
+		},
+	}
+	if result.Deactivated == nil {
+		result.Deactivated = []string{}
+	}
+
+	if err := s.reassignOpenReviews(ctx, tx, deactivated, models.ReasonUserDeactivated, &result.ReassignmentReport); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.recordAudit(ctx, tx, actor, models.AuditDeactivateTeamUsers, teamName, before, result); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceDeactivated, &result.ReassignmentReport)
+
+	return result, nil
+}
+
+// lockUsers читает пользователей по условию where с блокировкой строк до конца транзакции
+func (s *Storage) lockUsers(ctx context.Context, q querier, where string, params []interface{}) ([]models.User, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active
+		FROM users WHERE `+where+`
+		ORDER BY user_id
+		FOR UPDATE
+	`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// observeReassignments учитывает в метриках зафиксированный результат массового переназначения
+func observeReassignments(source string, report *models.ReassignmentReport) {
+	metrics.Reassignments.WithLabelValues(source).Add(float64(len(report.Reassigned)))
+	metrics.NoCandidate.WithLabelValues(source).Add(float64(len(report.NotReassigned)))
+}
+
+// reassignOpenReviews переназначает открытые ревью пользователей, которые уходят из
+// ротации (деактивированы, удалены из команды или переводятся в другую), по тем же
+// правилам, что и ReassignReviewer. Замена ищется в текущей команде ревьювера.
+// Все чтения и записи выполняются пачками, а нагрузка кандидатов пересчитывается
+// в памяти по мере назначения. reason попадает в историю PR.
+// PR, для которых не нашлось замены, остаются за пользователем и попадают в report.NotReassigned.
+func (s *Storage) reassignOpenReviews(ctx context.Context, q querier, userIDs []string, reason string, report *models.ReassignmentReport) error {
+	const op = "storage.reassignOpenReviews"
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	assignments, err := s.loadOpenAssignments(ctx, q, userIDs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	prIDs := make([]string, 0, len(assignments))
+	for _, a := range assignments {
+		prIDs = append(prIDs, a.prID)
+	}
+	currentReviewers, err := s.loadReviewerSets(ctx, q, unique(prIDs))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	policies := make(map[string]*models.TeamPolicy)
+	pools := make(map[string][]selector.Candidate)
+	// poolIndex — позиция кандидата в пуле его команды: нагрузка выбранного
+	// обновляется по ней, без повторного прохода по пулу
+	poolIndex := make(map[string]int)
+
+	getPool := func(team string) ([]selector.Candidate, error) {
+		if pool, ok := pools[team]; ok {
+			return pool, nil
+		}
+		pool, err := s.findCandidates(ctx, q, team, nil)
+		if err != nil {
+			return nil, err
+		}
+		pools[team] = pool
+		for i, c := range pool {
+			poolIndex[c.UserID] = i
+		}
+		return pool, nil
+	}
+
+	// буфер кандидатов переиспользуется между выборами
+	var available []selector.Candidate
+
+	pick := func(team string, strategy models.AssignmentStrategy, exclude map[string]bool) (string, error) {
+		pool, err := getPool(team)
+		if err != nil {
+			return "", err
+		}
+
+		available = available[:0]
+		for _, c := range pool {
+			if !exclude[c.UserID] {
+				available = append(available, c)
+			}
+		}
+
+		selected, err := s.selectReviewers(strategy, available, 1)
+		if err != nil || len(selected) == 0 {
+			return "", err
+		}
+
+		now := time.Now()
+		chosen := &pool[poolIndex[selected[0]]]
+		chosen.OpenReviews++
+		chosen.LastAssignedAt = &now
+		return selected[0], nil
+	}
+
+	var changes []reviewerChange
+	for _, a := range assignments {
+		policy, ok := policies[a.authorTeam]
+		if !ok {
+			policy, err = s.getTeamPolicy(ctx, q, a.authorTeam)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			policies[a.authorTeam] = policy
+		}
+
+		exclude := map[string]bool{a.authorID: true}
+		for reviewer := range currentReviewers[a.prID] {
+			exclude[reviewer] = true
+		}
+
+		newReviewer, err := pick(a.reviewerTeam, policy.Strategy, exclude)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		fromFallback := a.reviewerTeam != a.authorTeam
+
+		if newReviewer == "" && policy.AllowCrossTeam {
+			for _, team := range policy.FallbackTeams {
+				newReviewer, err = pick(team, policy.Strategy, exclude)
+				if err != nil {
+					return fmt.Errorf("%s: %w", op, err)
+				}
+				if newReviewer != "" {
+					fromFallback = true
+					break
+				}
+			}
+		}
+
+		if newReviewer == "" {
+			report.NotReassigned = append(report.NotReassigned, models.ReassignmentFailure{
+				PRID:        a.prID,
+				OldReviewer: a.reviewerID,
+				Reason:      ErrNoCandidate.Code,
+			})
+			continue
+		}
+
+		delete(currentReviewers[a.prID], a.reviewerID)
+		currentReviewers[a.prID][newReviewer] = true
+
+		changes = append(changes, reviewerChange{
+			prID:         a.prID,
+			oldReviewer:  a.reviewerID,
+			newReviewer:  newReviewer,
+			fromFallback: fromFallback,
+			strategy:     policy.Strategy,
+			reason:       reason,
+		})
+		report.Reassigned = append(report.Reassigned, models.Reassignment{
+			PRID:        a.prID,
+			OldReviewer: a.reviewerID,
+			NewReviewer: newReviewer,
+		})
+	}
+
+	// кандидаты читались без блокировок: если кого-то из них успели деактивировать,
+	// безопаснее отменить всю операцию, чем назначить неактивного ревьювера
+	newReviewers := make([]string, len(changes))
+	for i, c := range changes {
+		newReviewers[i] = c.newReviewer
+	}
+	inactive, err := s.lockActiveUsers(ctx, q, newReviewers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(inactive) > 0 {
+		return fmt.Errorf("%s: %w", op, ErrConcurrentUpdate)
+	}
+
+	if err := s.applyReviewerChanges(ctx, q, changes); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) loadOpenAssignments(ctx context.Context, q querier, userIDs []string) ([]openAssignment, error) {
+	var assignments []openAssignment
+
+	for _, chunk := range chunks(userIDs, batchSize) {
+		// CROSS JOIN фиксирует порядок соединения в SQLite: без него на длинном списке
+		// ревьюверов планировщик перебирает все открытые PR по индексу статуса.
+		// Postgres выполняет такой запрос как обычный JOIN.
+		rows, err := q.QueryContext(ctx, `
+			SELECT prr.pr_id, prr.reviewer_id, COALESCE(r.team_name, ''), pr.author_id, COALESCE(a.team_name, '')
+			FROM pr_reviewers prr
+			CROSS JOIN pull_requests pr
+			JOIN users r ON r.user_id = prr.reviewer_id
+			JOIN users a ON a.user_id = pr.author_id
+			WHERE pr.pull_request_id = prr.pr_id AND pr.status = $1 AND prr.reviewer_id IN (`+placeholders(2, len(chunk))+`)
+			ORDER BY pr.created_at, prr.pr_id, prr.reviewer_id
+			FOR UPDATE OF pr
+		`, append([]interface{}{models.StatusOpen}, stringArgs(chunk)...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var a openAssignment
+			if err := rows.Scan(&a.prID, &a.reviewerID, &a.reviewerTeam, &a.authorID, &a.authorTeam); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			assignments = append(assignments, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return assignments, nil
+}
+
+func (s *Storage) loadReviewerSets(ctx context.Context, q querier, prIDs []string) (map[string]map[string]bool, error) {
+	sets := make(map[string]map[string]bool, len(prIDs))
+	for _, prID := range prIDs {
+		sets[prID] = make(map[string]bool)
+	}
+
+	for _, chunk := range chunks(prIDs, batchSize) {
+		rows, err := q.QueryContext(ctx, `
+			SELECT pr_id, reviewer_id FROM pr_reviewers
+			WHERE pr_id IN (`+placeholders(1, len(chunk))+`)
+		`, stringArgs(chunk)...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var prID, reviewerID string
+			if err := rows.Scan(&prID, &reviewerID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			sets[prID][reviewerID] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sets, nil
+}
+
+func (s *Storage) applyReviewerChanges(ctx context.Context, q querier, changes []reviewerChange) error {
+	for start := 0; start < len(changes); start += batchSize {
+		end := min(start+batchSize, len(changes))
+		batch := changes[start:end]
+
+		pairs := make([]string, len(batch))
+		deleteParams := make([]interface{}, 0, len(batch)*2)
+		values := make([]string, len(batch))
+		insertParams := make([]interface{}, 0, len(batch)*3)
+		byStrategy := make(map[models.AssignmentStrategy][]string)
+		events := make([]models.PREvent, len(batch))
+
+		for i, c := range batch {
+			pairs[i] = fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2)
+			deleteParams = append(deleteParams, c.prID, c.oldReviewer)
+			values[i] = fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3)
+			insertParams = append(insertParams, c.prID, c.newReviewer, c.fromFallback)
+			byStrategy[c.strategy] = append(byStrategy[c.strategy], c.prID)
+			events[i] = models.PREvent{
+				PRID:          c.prID,
+				Type:          models.PREventReassigned,
+				OldReviewerID: c.oldReviewer,
+				NewReviewerID: c.newReviewer,
+				Reason:        c.reason,
+			}
+		}
+
+		_, err := q.ExecContext(ctx, `
+			DELETE FROM pr_reviewers WHERE (pr_id, reviewer_id) IN (VALUES `+strings.Join(pairs, ", ")+`)
+		`, deleteParams...)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO pr_reviewers (pr_id, reviewer_id, from_fallback) VALUES `+strings.Join(values, ", "),
+			insertParams...)
+		if err != nil {
+			return err
+		}
+
+		for strategy, prIDs := range byStrategy {
+			prIDs = unique(prIDs)
+			_, err = q.ExecContext(ctx, `
+				UPDATE pull_requests SET assignment_strategy = $1
+				WHERE pull_request_id IN (`+placeholders(2, len(prIDs))+`)
+			`, append([]interface{}{strategy}, stringArgs(prIDs)...)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.recordPREvents(ctx, q, events...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func placeholders(start, count int) string {
+	parts := make([]string, count)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func chunks(values []string, size int) [][]string {
+	var result [][]string
+	for start := 0; start < len(values); start += size {
+		result = append(result, values[start:min(start+size, len(values))])
+	}
+	return result
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, rows.Err()
+}
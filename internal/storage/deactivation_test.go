@@ -0,0 +1,143 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// Масштаб из требований к /team/deactivateUsers: команда из 200 человек и тысячи открытых PR
+const (
+	benchTeamSize    = 200
+	benchPRs         = 4000
+	benchDeactivated = 60
+)
+
+// seedDeactivation создаёт команду benchTeamSize и benchPRs открытых PR с двумя ревьюверами
+func seedDeactivation(tb testing.TB, repo storage.Repository) []string {
+	tb.Helper()
+
+	members := createTeam(tb, repo, "big", benchTeamSize)
+	for i := range benchPRs {
+		req := models.CreatePRRequest{
+			ID:       fmt.Sprintf("pr-%d", i),
+			Name:     "bench",
+			AuthorID: members[i%len(members)],
+		}
+		if _, err := repo.CreatePR(context.Background(), req, "bench"); err != nil {
+			tb.Fatalf("create %s: %v", req.ID, err)
+		}
+	}
+	return members
+}
+
+func TestDeactivateTeamUsersReassignsAll(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+
+			members := createTeam(t, repo, "core", 20)
+			for i := range 200 {
+				req := models.CreatePRRequest{ID: fmt.Sprintf("pr-%d", i), Name: "x", AuthorID: members[i%len(members)]}
+				if _, err := repo.CreatePR(ctx, req, "test"); err != nil {
+					t.Fatalf("create %s: %v", req.ID, err)
+				}
+			}
+
+			leaving := members[:10]
+			result, err := repo.DeactivateTeamUsers(ctx, "core", leaving, "test")
+			if err != nil {
+				t.Fatalf("deactivate: %v", err)
+			}
+			if len(result.Deactivated) != len(leaving) || len(result.NotReassigned) != 0 {
+				t.Fatalf("deactivated %d, not reassigned %d; want %d and 0",
+					len(result.Deactivated), len(result.NotReassigned), len(leaving))
+			}
+
+			for i := range 200 {
+				details, err := repo.GetPR(ctx, fmt.Sprintf("pr-%d", i))
+				if err != nil {
+					t.Fatalf("get: %v", err)
+				}
+				checkReviewers(t, details.PR)
+				for _, reviewer := range details.PR.AssignedReviewers {
+					for _, gone := range leaving {
+						if reviewer == gone {
+							t.Errorf("PR %s still reviewed by deactivated %s", details.PR.ID, gone)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDeactivateTeamUsers деактивирует benchDeactivated участников команды из
+// benchTeamSize человек с benchPRs открытыми PR. Каждая итерация начинается с копии
+// заранее заполненной базы, подготовка в замер не входит.
+func BenchmarkDeactivateTeamUsers(b *testing.B) {
+	b.Run("memory", func(b *testing.B) {
+		for b.Loop() {
+			b.StopTimer()
+			repo := openMemory(b)
+			members := seedDeactivation(b, repo)
+			b.StartTimer()
+
+			deactivate(b, repo, members[:benchDeactivated])
+		}
+	})
+
+	b.Run("sqlite", func(b *testing.B) {
+		template := filepath.Join(b.TempDir(), "template.db")
+		repo, closeDB := openSQLiteAt(b, template)
+		members := seedDeactivation(b, repo)
+		closeDB()
+
+		for b.Loop() {
+			b.StopTimer()
+			path := filepath.Join(b.TempDir(), "bench.db")
+			copyFile(b, template, path)
+			repo, closeDB := openSQLiteAt(b, path)
+			b.StartTimer()
+
+			deactivate(b, repo, members[:benchDeactivated])
+
+			b.StopTimer()
+			closeDB()
+			b.StartTimer()
+		}
+	})
+}
+
+func deactivate(b *testing.B, repo storage.Repository, userIDs []string) {
+	result, err := repo.DeactivateTeamUsers(context.Background(), "big", userIDs, "bench")
+	if err != nil {
+		b.Fatalf("deactivate: %v", err)
+	}
+	b.ReportMetric(float64(len(result.Reassigned)), "reassigned/op")
+}
+
+func copyFile(tb testing.TB, from, to string) {
+	tb.Helper()
+
+	src, err := os.Open(from)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer src.Close()
+	dst, err := os.Create(to)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		tb.Fatal(err)
+	}
+}
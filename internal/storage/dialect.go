@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dialect переводит запросы, написанные для PostgreSQL, и их аргументы в диалект конкретной СУБД
+type dialect interface {
+	bind(query string, args []interface{}) (string, []interface{})
+}
+
+// sqlDB и sqlTx пропускают все запросы хранилища через dialect
+type sqlDB struct {
+	*sql.DB
+	dialect dialect
+	// writer не nil, если СУБД допускает только одну пишущую транзакцию (SQLite).
+	// Транзакции ждут своей очереди здесь, а не в busy handler SQLite: тот опрашивает
+	// блокировку с паузами и под нагрузкой может не дождаться её до busy_timeout.
+	writer chan struct{}
+}
+
+type sqlTx struct {
+	*sql.Tx
+	dialect dialect
+	release func()
+	once    sync.Once
+}
+
+func (db *sqlDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sqlTx, error) {
+	release := func() {}
+	if db.writer != nil {
+		select {
+		case db.writer <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-db.writer }
+	}
+
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &sqlTx{Tx: tx, dialect: db.dialect, release: release}, nil
+}
+
+func (tx *sqlTx) Commit() error {
+	defer tx.once.Do(tx.release)
+	return tx.Tx.Commit()
+}
+
+func (tx *sqlTx) Rollback() error {
+	defer tx.once.Do(tx.release)
+	return tx.Tx.Rollback()
+}
+
+func (db *sqlDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = db.dialect.bind(query, args)
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+func (db *sqlDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = db.dialect.bind(query, args)
+	return db.DB.QueryContext(ctx, query, args...)
+}
+
+func (db *sqlDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = db.dialect.bind(query, args)
+	return db.DB.QueryRowContext(ctx, query, args...)
+}
+
+func (tx *sqlTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = tx.dialect.bind(query, args)
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+func (tx *sqlTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = tx.dialect.bind(query, args)
+	return tx.Tx.QueryContext(ctx, query, args...)
+}
+
+func (tx *sqlTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = tx.dialect.bind(query, args)
+	return tx.Tx.QueryRowContext(ctx, query, args...)
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) bind(query string, args []interface{}) (string, []interface{}) {
+	return query, args
+}
+
+// sqliteTimeLayout — формат хранения времени в SQLite. Всё время пишется в UTC,
+// поэтому строки сравниваются в том же порядке, что и метки времени.
+const sqliteTimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+// sqliteNow — аналог NOW() в формате sqliteTimeLayout (с точностью до миллисекунд)
+const sqliteNow = `strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')`
+
+var (
+	pgPlaceholder = regexp.MustCompile(`\$(\d+)`)
+	pgRowLock     = regexp.MustCompile(`\s+FOR\s+(?:UPDATE|SHARE)(?:\s+OF\s+\w+)?(?:\s+SKIP\s+LOCKED)?`)
+	pgHoursBefore = regexp.MustCompile(`\?::timestamptz - make_interval\(hours => ([\w.]+)\)`)
+)
+
+// sqliteDialect не поддерживает блокировки строк: они не нужны, потому что
+// транзакции SQLite открываются через BEGIN IMMEDIATE и пишущие транзакции
+// выполняются строго по одной (см. database.NewSQLite).
+type sqliteDialect struct{}
+
+func (sqliteDialect) bind(query string, args []interface{}) (string, []interface{}) {
+	// $N заменяется на анонимный ?, а аргументы раскладываются в порядке появления
+	// в запросе. Именованные ?N драйвер сопоставляет с аргументами перебором со
+	// строковым сравнением, и на bulk-запросах с тысячами параметров это квадратично.
+	bound := make([]interface{}, 0, len(args))
+	query = pgPlaceholder.ReplaceAllStringFunc(query, func(placeholder string) string {
+		n, _ := strconv.Atoi(placeholder[1:])
+		if n >= 1 && n <= len(args) {
+			bound = append(bound, sqliteValue(args[n-1]))
+		}
+		return "?"
+	})
+
+	query = pgRowLock.ReplaceAllString(query, "")
+	query = pgHoursBefore.ReplaceAllString(query, `strftime('%Y-%m-%d %H:%M:%f+00:00', ?, '-' || ${1} || ' hours')`)
+	query = strings.ReplaceAll(query, "NOW()", sqliteNow)
+	// LIKE в SQLite и так не различает регистр (для ASCII)
+	query = strings.ReplaceAll(query, "ILIKE", "LIKE")
+	return query, bound
+}
+
+// sqliteValue переводит время в формат sqliteTimeLayout
+func sqliteValue(arg interface{}) interface{} {
+	switch v := arg.(type) {
+	case time.Time:
+		return v.UTC().Format(sqliteTimeLayout)
+	case *time.Time:
+		if v != nil {
+			return v.UTC().Format(sqliteTimeLayout)
+		}
+		return nil
+	default:
+		return arg
+	}
+}
+
+// nullTime — sql.NullTime, который принимает и строку в формате sqliteTimeLayout:
+// SQLite распознаёт время только в колонках с объявленным типом, а результат
+// выражений вроде MAX(assigned_at) отдаёт как есть, строкой
+type nullTime struct {
+	sql.NullTime
+}
+
+func (t *nullTime) Scan(value interface{}) error {
+	if s, ok := value.(string); ok {
+		parsed, err := time.Parse(sqliteTimeLayout, s)
+		if err != nil {
+			return err
+		}
+		t.Time, t.Valid = parsed, true
+		return nil
+	}
+	return t.NullTime.Scan(value)
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"review-assignment/internal/models"
+)
+
+// EVENT METHODS
+
+func (s *Storage) GetPR(ctx context.Context, prID string) (*models.PRDetails, error) {
+	const op = "storage.GetPR"
+
+	pr, err := s.getPRWithReviewers(ctx, s.db, prID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reviews, err := s.getPRReviews(ctx, s.db, prID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	timeline, err := s.getPREvents(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.PRDetails{
+		PR:       pr,
+		Reviews:  reviews,
+		Timeline: timeline,
+	}, nil
+}
+
+func (s *Storage) getPRReviews(ctx context.Context, q querier, prID string) ([]models.Review, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT reviewer_id, state, state_updated_at
+		FROM pr_reviewers
+		WHERE pr_id = $1
+		ORDER BY assigned_at
+	`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.Review{}
+	for rows.Next() {
+		review := models.Review{PRID: prID}
+		var state string
+		if err := rows.Scan(&review.ReviewerID, &state, &review.UpdatedAt); err != nil {
+			return nil, err
+		}
+		review.State = models.ReviewState(state)
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+func (s *Storage) getPREvents(ctx context.Context, prID string) ([]models.PREvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, pr_id, event_type, COALESCE(reviewer_id, ''), COALESCE(old_reviewer_id, ''),
+			COALESCE(new_reviewer_id, ''), COALESCE(state, ''), reason, created_at
+		FROM pr_events
+		WHERE pr_id = $1
+		ORDER BY created_at, id
+	`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.PREvent{}
+	for rows.Next() {
+		var e models.PREvent
+		var eventType, state string
+		if err := rows.Scan(&e.ID, &e.PRID, &eventType, &e.ReviewerID, &e.OldReviewerID,
+			&e.NewReviewerID, &state, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Type = models.PREventType(eventType)
+		e.State = models.ReviewState(state)
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// recordPREvents дописывает события в историю PR в рамках переданной транзакции
+func (s *Storage) recordPREvents(ctx context.Context, q querier, events ...models.PREvent) error {
+	const columns = 7
+
+	for start := 0; start < len(events); start += batchSize {
+		batch := events[start:min(start+batchSize, len(events))]
+
+		values := make([]string, len(batch))
+		params := make([]interface{}, 0, len(batch)*columns)
+		for i, e := range batch {
+			n := i * columns
+			values[i] = fmt.Sprintf("($%d, $%d, NULLIF($%d, ''), NULLIF($%d, ''), NULLIF($%d, ''), NULLIF($%d, ''), $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7)
+			params = append(params, e.PRID, e.Type, e.ReviewerID, e.OldReviewerID, e.NewReviewerID, e.State, e.Reason)
+		}
+
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO pr_events (pr_id, event_type, reviewer_id, old_reviewer_id, new_reviewer_id, state, reason)
+			VALUES `+strings.Join(values, ", "), params...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.enqueueWebhooks(ctx, q, events)
+}
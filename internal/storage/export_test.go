@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"review-assignment/internal/models"
+)
+
+// OverdueBatch — выборка просроченных ревью, которую тест обрабатывает после своих изменений
+type OverdueBatch []pendingReview
+
+// LoadOverdueReviews и ProcessOverdueBatch — шаги ProcessOverdueReviews по отдельности
+func (s *Storage) LoadOverdueReviews(ctx context.Context, now time.Time) (OverdueBatch, error) {
+	return s.loadOverdueReviews(ctx, now)
+}
+
+func (s *Storage) ProcessOverdueBatch(ctx context.Context, batch OverdueBatch, actor string) ([]models.SLAEvent, error) {
+	return s.processOverdueReviews(ctx, batch, actor)
+}
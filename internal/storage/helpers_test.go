@@ -0,0 +1,143 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"review-assignment/internal/database"
+	"review-assignment/internal/lib/businesstime"
+	"review-assignment/internal/migrations"
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// backends — реализации Repository для тестов. PostgreSQL нужен внешний сервер,
+// без TEST_POSTGRES_DSN его подтесты пропускаются.
+var backends = []struct {
+	name string
+	open func(tb testing.TB) storage.Repository
+}{
+	{"memory", openMemory},
+	{"sqlite", openSQLite},
+	{"postgres", openPostgres},
+}
+
+func openMemory(tb testing.TB) storage.Repository {
+	return storage.NewMemory(businesstime.Default())
+}
+
+func openSQLite(tb testing.TB) storage.Repository {
+	tb.Helper()
+
+	repo, closeDB := openSQLiteAt(tb, filepath.Join(tb.TempDir(), "test.db"))
+	tb.Cleanup(closeDB)
+	return repo
+}
+
+// openSQLiteAt открывает файл SQLite и применяет миграции; closeDB закрывает базу
+func openSQLiteAt(tb testing.TB, path string) (repo storage.Repository, closeDB func()) {
+	tb.Helper()
+
+	db, err := database.NewSQLite(path)
+	if err != nil {
+		tb.Fatalf("open sqlite: %v", err)
+	}
+
+	migrator, err := migrations.NewSQLite(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		tb.Fatalf("create migrator: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		tb.Fatalf("apply migrations: %v", err)
+	}
+
+	return storage.NewSQLite(db, businesstime.Default()), func() { db.Close() }
+}
+
+// openPostgres подключается к TEST_POSTGRES_DSN и применяет миграции в отдельной схеме,
+// которая удаляется после теста
+func openPostgres(tb testing.TB) storage.Repository {
+	tb.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		tb.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		var err error
+		if dsn, err = pq.ParseURL(dsn); err != nil {
+			tb.Fatalf("parse TEST_POSTGRES_DSN: %v", err)
+		}
+	}
+
+	admin, err := database.New(dsn)
+	if err != nil {
+		tb.Fatalf("open postgres: %v", err)
+	}
+	schema := fmt.Sprintf("test_%x", rand.Uint64())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		tb.Fatalf("create schema: %v", err)
+	}
+	tb.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	db, err := database.New(dsn + " search_path=" + schema)
+	if err != nil {
+		tb.Fatalf("open postgres schema %s: %v", schema, err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		tb.Fatalf("create migrator: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		tb.Fatalf("apply migrations: %v", err)
+	}
+
+	return storage.New(db, businesstime.Default())
+}
+
+// createTeam создаёт команду из size активных участников с id вида <name>-<i>
+func createTeam(tb testing.TB, repo storage.Repository, name string, size int) []string {
+	tb.Helper()
+
+	team := models.Team{Name: name}
+	ids := make([]string, size)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s-%d", name, i)
+		team.Members = append(team.Members, models.User{ID: ids[i], Username: ids[i], IsActive: true})
+	}
+	if err := repo.CreateTeam(context.Background(), team, "test"); err != nil {
+		tb.Fatalf("create team %s: %v", name, err)
+	}
+	return ids
+}
+
+// checkReviewers проверяет инварианты назначения: ревьюверы не повторяются и автор не ревьюит свой PR
+func checkReviewers(tb testing.TB, pr *models.PullRequest) {
+	tb.Helper()
+
+	seen := make(map[string]bool, len(pr.AssignedReviewers))
+	for _, reviewer := range pr.AssignedReviewers {
+		if reviewer == pr.AuthorID {
+			tb.Errorf("PR %s: author %s is assigned as reviewer %v", pr.ID, pr.AuthorID, pr.AssignedReviewers)
+		}
+		if seen[reviewer] {
+			tb.Errorf("PR %s: reviewer %s assigned twice %v", pr.ID, reviewer, pr.AssignedReviewers)
+		}
+		seen[reviewer] = true
+	}
+}
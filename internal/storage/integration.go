@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"review-assignment/internal/apperr"
+	"review-assignment/internal/models"
+)
+
+var integrationProviders = map[models.IntegrationProvider]bool{
+	models.ProviderGitHub: true,
+	models.ProviderGitLab: true,
+}
+
+// INTEGRATION METHODS
+
+// SetExternalAccount связывает логин во внешней системе с пользователем сервиса.
+// Логины сравниваются без учёта регистра. Если external_id не передан, у существующей связи он не меняется.
+func (s *Storage) SetExternalAccount(ctx context.Context, req models.SetExternalAccountRequest) (*models.ExternalAccount, error) {
+	const op = "storage.SetExternalAccount"
+
+	if !integrationProviders[req.Provider] {
+		return nil, fmt.Errorf("%s: %w", op, ErrUnknownProvider)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := s.ensureUserExists(ctx, tx, req.UserID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	account := models.ExternalAccount{
+		Provider: req.Provider,
+		Login:    strings.ToLower(req.Login),
+		UserID:   req.UserID,
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO external_accounts (provider, login, external_id, user_id) VALUES ($1, $2, NULLIF($3, ''), $4)
+		ON CONFLICT (provider, login) DO UPDATE SET
+			external_id = COALESCE(EXCLUDED.external_id, external_accounts.external_id),
+			user_id = EXCLUDED.user_id
+		RETURNING COALESCE(external_id, ''), created_at
+	`, account.Provider, account.Login, req.ExternalID, account.UserID).Scan(&account.ExternalID, &account.CreatedAt)
+	if err != nil {
+		if apperr.IsUniqueViolation(err, "external_accounts_provider_external_id_key") {
+			return nil, fmt.Errorf("%s: %w", op, ErrExternalIDTaken)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &account, nil
+}
+
+func (s *Storage) ListExternalAccounts(ctx context.Context, provider models.IntegrationProvider) ([]models.ExternalAccount, error) {
+	const op = "storage.ListExternalAccounts"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT provider, login, COALESCE(external_id, ''), user_id, created_at
+		FROM external_accounts
+		WHERE $1 = '' OR provider = $1
+		ORDER BY provider, login
+	`, provider)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	accounts := []models.ExternalAccount{}
+	for rows.Next() {
+		var account models.ExternalAccount
+		var providerStr string
+		if err := rows.Scan(&providerStr, &account.Login, &account.ExternalID, &account.UserID, &account.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		account.Provider = models.IntegrationProvider(providerStr)
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (s *Storage) RemoveExternalAccount(ctx context.Context, provider models.IntegrationProvider, login string) error {
+	const op = "storage.RemoveExternalAccount"
+
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM external_accounts WHERE provider = $1 AND login = $2
+	`, provider, strings.ToLower(login))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, ErrAccountNotFound)
+	}
+
+	return nil
+}
+
+// ResolveExternalAccount возвращает user_id, связанный с логином во внешней системе
+func (s *Storage) ResolveExternalAccount(ctx context.Context, provider models.IntegrationProvider, login string) (string, error) {
+	const op = "storage.ResolveExternalAccount"
+
+	var userID string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id FROM external_accounts WHERE provider = $1 AND login = $2
+	`, provider, strings.ToLower(login)).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%s: %w", op, ErrUnknownAccount)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+// ResolveExternalAccountByID возвращает user_id, связанный с id пользователя во внешней системе
+func (s *Storage) ResolveExternalAccountByID(ctx context.Context, provider models.IntegrationProvider, externalID string) (string, error) {
+	const op = "storage.ResolveExternalAccountByID"
+
+	var userID string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id FROM external_accounts WHERE provider = $1 AND external_id = $2
+	`, provider, externalID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%s: %w", op, ErrUnknownAccount)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+// ExternalReviewers сопоставляет пользователям их логины во внешней системе.
+// Для пользователей без связи Login остаётся пустым.
+func (s *Storage) ExternalReviewers(ctx context.Context, provider models.IntegrationProvider, userIDs []string) ([]models.ExternalReviewer, error) {
+	const op = "storage.ExternalReviewers"
+
+	reviewers := make([]models.ExternalReviewer, len(userIDs))
+	if len(userIDs) == 0 {
+		return reviewers, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT user_id, login FROM external_accounts
+		WHERE provider = $1 AND user_id IN (`+placeholders(2, len(userIDs))+`)
+		ORDER BY login
+	`, append([]interface{}{provider}, stringArgs(userIDs)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	logins := make(map[string]string, len(userIDs))
+	for rows.Next() {
+		var userID, login string
+		if err := rows.Scan(&userID, &login); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if _, ok := logins[userID]; !ok {
+			logins[userID] = login
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, userID := range userIDs {
+		reviewers[i] = models.ExternalReviewer{UserID: userID, Login: logins[userID]}
+	}
+
+	return reviewers, nil
+}
+
+// Состояния входящей доставки в integration_deliveries
+const (
+	deliveryProcessing = "PROCESSING"
+	deliveryDone       = "DONE"
+)
+
+// ClaimDelivery захватывает входящую доставку на время обработки, не дольше lease.
+// Возвращает false, если доставка уже обработана, и ErrDeliveryInProgress, если её
+// сейчас обрабатывает другой запрос. Захват, не завершённый за lease (например, процесс упал),
+// истекает, и доставку можно захватить снова.
+func (s *Storage) ClaimDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID, event string, lease time.Duration) (bool, error) {
+	const op = "storage.ClaimDelivery"
+
+	now := time.Now()
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO integration_deliveries (provider, delivery_id, event, status, lease_until)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider, delivery_id) DO UPDATE SET
+			event = EXCLUDED.event,
+			lease_until = EXCLUDED.lease_until,
+			received_at = $6
+		WHERE integration_deliveries.status = $4 AND integration_deliveries.lease_until < $6
+	`, provider, deliveryID, event, deliveryProcessing, now.Add(lease), now)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if affected > 0 {
+		return true, nil
+	}
+
+	var status string
+	err = s.db.QueryRowContext(ctx, `
+		SELECT status FROM integration_deliveries WHERE provider = $1 AND delivery_id = $2
+	`, provider, deliveryID).Scan(&status)
+	// захват мог только что сняться после ошибки: отправитель повторит доставку
+	if err == sql.ErrNoRows || status == deliveryProcessing {
+		return false, fmt.Errorf("%s: %w", op, ErrDeliveryInProgress)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return false, nil
+}
+
+// CompleteDelivery отмечает захваченную доставку обработанной: её повторы будут пропускаться
+func (s *Storage) CompleteDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID string) error {
+	const op = "storage.CompleteDelivery"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE integration_deliveries SET status = $1, lease_until = NULL
+		WHERE provider = $2 AND delivery_id = $3
+	`, deliveryDone, provider, deliveryID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReleaseDelivery снимает захват, чтобы повторная доставка того же события была обработана
+func (s *Storage) ReleaseDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID string) error {
+	const op = "storage.ReleaseDelivery"
+
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM integration_deliveries WHERE provider = $1 AND delivery_id = $2 AND status = $3
+	`, provider, deliveryID, deliveryProcessing)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// TestResolveExternalAccountByID проверяет связь по id во внешней системе,
+// по которой GitLab определяет автора MR
+func TestResolveExternalAccountByID(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+
+			members := createTeam(t, repo, "core", 2)
+			link := func(login, externalID, userID string) (*models.ExternalAccount, error) {
+				return repo.SetExternalAccount(ctx, models.SetExternalAccountRequest{
+					Provider:   models.ProviderGitLab,
+					Login:      login,
+					ExternalID: externalID,
+					UserID:     userID,
+				})
+			}
+
+			if _, err := link("Alice", "42", members[0]); err != nil {
+				t.Fatalf("link: %v", err)
+			}
+			// повторная связь без external_id не стирает его
+			account, err := link("alice", "", members[1])
+			if err != nil {
+				t.Fatalf("relink: %v", err)
+			}
+			if account.ExternalID != "42" {
+				t.Errorf("external_id = %q after relink, want 42", account.ExternalID)
+			}
+
+			userID, err := repo.ResolveExternalAccountByID(ctx, models.ProviderGitLab, "42")
+			if err != nil || userID != members[1] {
+				t.Errorf("resolve 42 = %q, %v; want %s", userID, err, members[1])
+			}
+			if _, err := repo.ResolveExternalAccountByID(ctx, models.ProviderGitHub, "42"); !errors.Is(err, storage.ErrUnknownAccount) {
+				t.Errorf("resolve on another provider: got %v, want %v", err, storage.ErrUnknownAccount)
+			}
+			if _, err := repo.ResolveExternalAccountByID(ctx, models.ProviderGitLab, "7"); !errors.Is(err, storage.ErrUnknownAccount) {
+				t.Errorf("resolve unknown id: got %v, want %v", err, storage.ErrUnknownAccount)
+			}
+
+			if _, err := link("bob", "42", members[0]); !errors.Is(err, storage.ErrExternalIDTaken) {
+				t.Errorf("link taken external_id: got %v, want %v", err, storage.ErrExternalIDTaken)
+			}
+		})
+	}
+}
+
+// TestClaimDelivery проверяет захват входящих доставок: повтор во время обработки получает
+// ErrDeliveryInProgress, обработанная доставка пропускается, а истёкший захват можно взять снова
+func TestClaimDelivery(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+			claim := func(deliveryID string, lease time.Duration) (bool, error) {
+				return repo.ClaimDelivery(ctx, models.ProviderGitHub, deliveryID, "pull_request", lease)
+			}
+
+			if claimed, err := claim("d-1", time.Minute); !claimed || err != nil {
+				t.Fatalf("first claim = %v, %v; want claimed", claimed, err)
+			}
+			if _, err := claim("d-1", time.Minute); !errors.Is(err, storage.ErrDeliveryInProgress) {
+				t.Errorf("claim in flight: got %v, want %v", err, storage.ErrDeliveryInProgress)
+			}
+			if err := repo.CompleteDelivery(ctx, models.ProviderGitHub, "d-1"); err != nil {
+				t.Fatalf("complete: %v", err)
+			}
+			if claimed, err := claim("d-1", time.Minute); claimed || err != nil {
+				t.Errorf("claim after complete = %v, %v; want duplicate", claimed, err)
+			}
+			// снять захват с обработанной доставки нельзя
+			if err := repo.ReleaseDelivery(ctx, models.ProviderGitHub, "d-1"); err != nil {
+				t.Fatalf("release done: %v", err)
+			}
+			if claimed, err := claim("d-1", time.Minute); claimed || err != nil {
+				t.Errorf("claim after release of done delivery = %v, %v; want duplicate", claimed, err)
+			}
+
+			if claimed, err := claim("d-2", time.Minute); !claimed || err != nil {
+				t.Fatalf("claim d-2 = %v, %v", claimed, err)
+			}
+			if err := repo.ReleaseDelivery(ctx, models.ProviderGitHub, "d-2"); err != nil {
+				t.Fatalf("release: %v", err)
+			}
+			if claimed, err := claim("d-2", time.Minute); !claimed || err != nil {
+				t.Errorf("claim after release = %v, %v; want claimed", claimed, err)
+			}
+
+			// захват упавшего процесса истекает
+			if claimed, err := claim("d-3", time.Millisecond); !claimed || err != nil {
+				t.Fatalf("claim d-3 = %v, %v", claimed, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if claimed, err := claim("d-3", time.Minute); !claimed || err != nil {
+				t.Errorf("claim after lease expired = %v, %v; want claimed", claimed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"review-assignment/internal/models"
+)
+
+// transitions описывает допустимые переходы между статусами PR
+var transitions = map[models.PRStatus][]models.PRStatus{
+	models.StatusDraft:  {models.StatusOpen, models.StatusClosed},
+	models.StatusOpen:   {models.StatusMerged, models.StatusClosed},
+	models.StatusClosed: {models.StatusOpen},
+	models.StatusMerged: {},
+}
+
+func canTransition(from, to models.PRStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// LIFECYCLE METHODS
+
+// MarkReady переводит DRAFT в OPEN и назначает ревьюверов
+func (s *Storage) MarkReady(ctx context.Context, prID, actor string) (*models.PullRequest, error) {
+	const op = "storage.MarkReady"
+
+	pr, err := s.transitionPR(ctx, prID, models.StatusDraft, models.StatusOpen, actor)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return pr, nil
+}
+
+// ReopenPR переводит CLOSED в OPEN и заново назначает ревьюверов
+func (s *Storage) ReopenPR(ctx context.Context, prID, actor string) (*models.PullRequest, error) {
+	const op = "storage.ReopenPR"
+
+	pr, err := s.transitionPR(ctx, prID, models.StatusClosed, models.StatusOpen, actor)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return pr, nil
+}
+
+// ClosePR закрывает PR без merge и снимает с него ревьюверов с событиями UNASSIGNED
+func (s *Storage) ClosePR(ctx context.Context, prID, actor string) (*models.PullRequest, error) {
+	const op = "storage.ClosePR"
+
+	pr, err := s.transitionPR(ctx, prID, "", models.StatusClosed, actor)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return pr, nil
+}
+
+// transitionPR переводит PR в статус to. Если from задан, исходный статус обязан с ним совпадать,
+// иначе (закрытие) повторный перевод в текущий статус идемпотентен.
+func (s *Storage) transitionPR(ctx context.Context, prID string, from, to models.PRStatus, actor string) (*models.PullRequest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.lockPR(ctx, tx, prID); err != nil {
+		return nil, err
+	}
+
+	pr, err := s.getPRWithReviewers(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.Status == to && from == "" {
+		return pr, nil
+	}
+	if (from != "" && pr.Status != from) || !canTransition(pr.Status, to) {
+		return nil, ErrInvalidTransition
+	}
+
+	eventType, operation := models.PREventClosed, models.AuditClosePR
+	if to == models.StatusOpen {
+		eventType, operation = models.PREventReady, models.AuditMarkReady
+		if pr.Status == models.StatusClosed {
+			eventType, operation = models.PREventReopened, models.AuditReopenPR
+		}
+	}
+	if err := s.recordPREvents(ctx, tx, models.PREvent{PRID: prID, Type: eventType}); err != nil {
+		return nil, err
+	}
+
+	before := *pr
+	switch to {
+	case models.StatusClosed:
+		// снятые ревьюверы вместе с состояниями их ревью остаются в истории PR
+		reviews, err := s.getPRReviews(ctx, tx, prID)
+		if err != nil {
+			return nil, err
+		}
+		var unassigned []models.PREvent
+		for _, review := range reviews {
+			unassigned = append(unassigned, models.PREvent{
+				PRID:       prID,
+				Type:       models.PREventUnassigned,
+				ReviewerID: review.ReviewerID,
+				State:      review.State,
+				Reason:     models.ReasonPRClosed,
+			})
+		}
+		if err := s.recordPREvents(ctx, tx, unassigned...); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM pr_reviewers WHERE pr_id = $1`, prID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE pull_requests SET status = $1, closed_at = NOW()
+			WHERE pull_request_id = $2
+			RETURNING closed_at
+		`, to, prID).Scan(&pr.ClosedAt)
+		if err != nil {
+			return nil, err
+		}
+		pr.AssignedReviewers = []string{}
+		pr.FallbackReviewers = nil
+
+	case models.StatusOpen:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE pull_requests SET status = $1, closed_at = NULL
+			WHERE pull_request_id = $2
+		`, to, prID)
+		if err != nil {
+			return nil, err
+		}
+
+		var authorTeam string
+		err = tx.QueryRowContext(ctx, `
+			SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1
+		`, pr.AuthorID).Scan(&authorTeam)
+		if err != nil {
+			return nil, err
+		}
+
+		pr.ClosedAt = nil
+		if err := s.assignReviewers(ctx, tx, pr, authorTeam); err != nil {
+			return nil, err
+		}
+	}
+
+	pr.Status = to
+	if err := s.recordAudit(ctx, tx, actor, operation, prID, before, pr); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
@@ -0,0 +1,76 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// TestPRLifecycle проверяет переходы статусов PR: повторы, недопустимые переходы
+// и сохранение состояний ревью в истории при закрытии
+func TestPRLifecycle(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+
+			members := createTeam(t, repo, "core", 4)
+			pr, err := repo.CreatePR(ctx, models.CreatePRRequest{ID: "pr-1", Name: "x", AuthorID: members[0]}, "test")
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			if len(pr.AssignedReviewers) == 0 {
+				t.Fatal("open PR has no reviewers")
+			}
+
+			// ready и reopen допустимы только из DRAFT и CLOSED
+			if _, err := repo.MarkReady(ctx, "pr-1", "test"); !errors.Is(err, storage.ErrInvalidTransition) {
+				t.Errorf("MarkReady on OPEN: got %v, want %v", err, storage.ErrInvalidTransition)
+			}
+			if _, err := repo.ReopenPR(ctx, "pr-1", "test"); !errors.Is(err, storage.ErrInvalidTransition) {
+				t.Errorf("ReopenPR on OPEN: got %v, want %v", err, storage.ErrInvalidTransition)
+			}
+
+			reviewer := pr.AssignedReviewers[0]
+			review := models.SubmitReviewRequest{PRID: "pr-1", ReviewerID: reviewer, State: models.ReviewApproved}
+			if _, err := repo.SubmitReview(ctx, review, "test"); err != nil {
+				t.Fatalf("review: %v", err)
+			}
+
+			if _, err := repo.ClosePR(ctx, "pr-1", "test"); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+			// повторное закрытие идемпотентно и отдаёт пустой, а не null список ревьюверов
+			closed, err := repo.ClosePR(ctx, "pr-1", "test")
+			if err != nil {
+				t.Fatalf("repeated close: %v", err)
+			}
+			if closed.AssignedReviewers == nil || len(closed.AssignedReviewers) != 0 {
+				t.Errorf("closed PR reviewers = %#v, want empty list", closed.AssignedReviewers)
+			}
+
+			details, err := repo.GetPR(ctx, "pr-1")
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			unassigned := make(map[string]models.ReviewState)
+			for _, event := range details.Timeline {
+				if event.Type == models.PREventUnassigned {
+					if event.Reason != models.ReasonPRClosed {
+						t.Errorf("UNASSIGNED %s with reason %q, want %q", event.ReviewerID, event.Reason, models.ReasonPRClosed)
+					}
+					unassigned[event.ReviewerID] = event.State
+				}
+			}
+			if len(unassigned) != len(pr.AssignedReviewers) {
+				t.Errorf("got UNASSIGNED for %v, want all of %v", unassigned, pr.AssignedReviewers)
+			}
+			if unassigned[reviewer] != models.ReviewApproved {
+				t.Errorf("UNASSIGNED %s state = %q, want %q", reviewer, unassigned[reviewer], models.ReviewApproved)
+			}
+		})
+	}
+}
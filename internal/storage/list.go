@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"review-assignment/internal/models"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// LIST METHODS
+
+// ListPRs возвращает PR, отсортированные по (created_at, pull_request_id) по убыванию.
+// Пагинация курсорная: курсор кодирует ключ последнего PR страницы.
+func (s *Storage) ListPRs(ctx context.Context, filter models.PRListFilter) (*models.PRListPage, error) {
+	const op = "storage.ListPRs"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	var conditions []string
+	var params []interface{}
+	arg := func(v interface{}) string {
+		params = append(params, v)
+		return fmt.Sprintf("$%d", len(params))
+	}
+
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidCursor)
+		}
+		conditions = append(conditions, fmt.Sprintf("(pr.created_at, pr.pull_request_id) < (%s, %s)", arg(createdAt), arg(id)))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = arg(status)
+		}
+		conditions = append(conditions, "pr.status IN ("+strings.Join(statuses, ", ")+")")
+	}
+	if filter.AuthorID != "" {
+		conditions = append(conditions, "pr.author_id = "+arg(filter.AuthorID))
+	}
+	if filter.ReviewerID != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM pr_reviewers prr
+			WHERE prr.pr_id = pr.pull_request_id AND prr.reviewer_id = `+arg(filter.ReviewerID)+`)`)
+	}
+	if filter.TeamName != "" {
+		conditions = append(conditions, "pr.author_id IN (SELECT user_id FROM users WHERE team_name = "+arg(filter.TeamName)+")")
+	}
+	if filter.NameContains != "" {
+		conditions = append(conditions, "pr.pull_request_name ILIKE "+arg("%"+escapeLike(filter.NameContains)+"%")+` ESCAPE '\'`)
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "pr.created_at >= "+arg(filter.CreatedFrom.UTC()))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "pr.created_at < "+arg(filter.CreatedTo.UTC()))
+	}
+	if filter.MergedFrom != nil {
+		conditions = append(conditions, "pr.merged_at >= "+arg(filter.MergedFrom.UTC()))
+	}
+	if filter.MergedTo != nil {
+		conditions = append(conditions, "pr.merged_at < "+arg(filter.MergedTo.UTC()))
+	}
+
+	query := `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
+			pr.assignment_strategy, pr.created_at, pr.merged_at, pr.closed_at
+		FROM pull_requests pr
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// запрашиваем на одну строку больше, чтобы понять, есть ли следующая страница
+	query += " ORDER BY pr.created_at DESC, pr.pull_request_id DESC LIMIT " + arg(limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	prs := []models.PullRequest{}
+	for rows.Next() {
+		var pr models.PullRequest
+		var statusStr string
+		var strategy sql.NullString
+		var mergedAt, closedAt sql.NullTime
+
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &statusStr,
+			&strategy, &pr.CreatedAt, &mergedAt, &closedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		pr.Status = models.PRStatus(statusStr)
+		pr.Strategy = models.AssignmentStrategy(strategy.String)
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		if closedAt.Valid {
+			pr.ClosedAt = &closedAt.Time
+		}
+		pr.AssignedReviewers = []string{}
+		prs = append(prs, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := &models.PRListPage{}
+	if len(prs) > limit {
+		prs = prs[:limit]
+		last := prs[len(prs)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	if err := s.attachReviewers(ctx, prs); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	page.PullRequests = prs
+
+	return page, nil
+}
+
+// attachReviewers загружает ревьюверов для страницы PR одним запросом
+func (s *Storage) attachReviewers(ctx context.Context, prs []models.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(prs))
+	ids := make([]string, len(prs))
+	for i, pr := range prs {
+		index[pr.ID] = i
+		ids[i] = pr.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT pr_id, reviewer_id, from_fallback
+		FROM pr_reviewers
+		WHERE pr_id IN (`+placeholders(1, len(ids))+`)
+		ORDER BY assigned_at
+	`, stringArgs(ids)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var prID, reviewerID string
+		var fromFallback bool
+		if err := rows.Scan(&prID, &reviewerID, &fromFallback); err != nil {
+			return err
+		}
+
+		pr := &prs[index[prID]]
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+		if fromFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, reviewerID)
+		}
+	}
+
+	return rows.Err()
+}
+
+func encodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	createdAtStr, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	return createdAt, id, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/models"
+)
+
+// RemoveTeamMember убирает пользователя из команды. Пользователь остаётся в users
+// без команды и деактивированным: на него ссылаются PR, ревью и журналы.
+// Автора открытых или черновых PR удалить нельзя, его открытые ревью переназначаются.
+func (s *Storage) RemoveTeamMember(ctx context.Context, teamName, userID, actor string) (*models.TeamMemberChange, error) {
+	const op = "storage.RemoveTeamMember"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	before, err := s.lockUser(ctx, tx, userID)
+	if err == ErrUserNotFound || (err == nil && before.TeamName != teamName) {
+		return nil, fmt.Errorf("%s: %w", op, ErrMemberNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var authorsOpenPRs bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM pull_requests WHERE author_id = $1 AND status IN ($2, $3))
+	`, userID, models.StatusOpen, models.StatusDraft).Scan(&authorsOpenPRs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if authorsOpenPRs {
+		return nil, fmt.Errorf("%s: %w", op, ErrMemberHasOpenPRs)
+	}
+
+	result := newTeamMemberChange(teamName)
+
+	// замена ищется в команде ревьювера, поэтому команда снимается только после переназначения
+	if err := s.reassignOpenReviews(ctx, tx, []string{userID}, models.ReasonMemberRemoved, &result.ReassignmentReport); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users
+		SET team_name = NULL, is_active = false, updated_at = NOW()
+		WHERE user_id = $1
+		RETURNING user_id, username, COALESCE(team_name, ''), is_active
+	`, userID).Scan(&result.User.ID, &result.User.Username, &result.User.TeamName, &result.User.IsActive)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.recordAudit(ctx, tx, actor, models.AuditRemoveTeamMember, userID, before, result); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceTeamChange, &result.ReassignmentReport)
+
+	return result, nil
+}
+
+// MoveTeamMember переводит пользователя в другую команду. Открытые ревью остаются
+// за ним (keep) или переназначаются на участников прежней команды (reassign).
+// Пользователя без команды этим же методом можно вернуть в команду; is_active не меняется.
+func (s *Storage) MoveTeamMember(ctx context.Context, req models.MoveTeamMemberRequest, actor string) (*models.TeamMemberChange, error) {
+	const op = "storage.MoveTeamMember"
+
+	if req.OpenReviews != models.OpenReviewsKeep && req.OpenReviews != models.OpenReviewsReassign {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidOpenReviews)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	before, err := s.lockUser(ctx, tx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if before.TeamName == req.ToTeam {
+		return nil, fmt.Errorf("%s: %w", op, ErrSameTeam)
+	}
+
+	var toTeam string
+	err = tx.QueryRowContext(ctx, `
+		SELECT name FROM teams WHERE name = $1
+		FOR SHARE
+	`, req.ToTeam).Scan(&toTeam)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", op, ErrTeamNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := newTeamMemberChange(before.TeamName)
+
+	// замена ищется в команде ревьювера, поэтому переназначение идёт до перевода
+	if req.OpenReviews == models.OpenReviewsReassign {
+		if err := s.reassignOpenReviews(ctx, tx, []string{req.UserID}, models.ReasonMemberMoved, &result.ReassignmentReport); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users
+		SET team_name = $1, updated_at = NOW()
+		WHERE user_id = $2
+		RETURNING user_id, username, COALESCE(team_name, ''), is_active
+	`, toTeam, req.UserID).Scan(&result.User.ID, &result.User.Username, &result.User.TeamName, &result.User.IsActive)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.recordAudit(ctx, tx, actor, models.AuditMoveTeamMember, req.UserID, before, result); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceTeamChange, &result.ReassignmentReport)
+
+	return result, nil
+}
+
+// DeleteTeam удаляет команду без участников. Вместе с ней удаляются её резервные
+// команды, упоминания в чужих списках резервных команд и подписки на её события.
+// Участников нужно заранее удалить (RemoveTeamMember) или перевести (MoveTeamMember).
+func (s *Storage) DeleteTeam(ctx context.Context, teamName, actor string) error {
+	const op = "storage.DeleteTeam"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// блокировка команды не даёт параллельно добавить в неё участников
+	var name string
+	err = tx.QueryRowContext(ctx, `
+		SELECT name FROM teams WHERE name = $1
+		FOR UPDATE
+	`, teamName).Scan(&name)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%s: %w", op, ErrTeamNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var hasMembers bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM users WHERE team_name = $1)
+	`, teamName).Scan(&hasMembers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if hasMembers {
+		return fmt.Errorf("%s: %w", op, ErrTeamNotEmpty)
+	}
+
+	before, err := s.getTeamPolicy(ctx, tx, teamName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM teams WHERE name = $1`, teamName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.recordAudit(ctx, tx, actor, models.AuditDeleteTeam, teamName, before, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// lockUser читает пользователя с блокировкой строки до конца транзакции
+func (s *Storage) lockUser(ctx context.Context, q querier, userID string) (*models.User, error) {
+	var user models.User
+	err := q.QueryRowContext(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active
+		FROM users WHERE user_id = $1
+		FOR UPDATE
+	`, userID).Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func newTeamMemberChange(fromTeam string) *models.TeamMemberChange {
+	return &models.TeamMemberChange{
+		FromTeam: fromTeam,
+		ReassignmentReport: models.ReassignmentReport{
+			Reassigned:    []models.Reassignment{},
+			NotReassigned: []models.ReassignmentFailure{},
+		},
+	}
+}
@@ -0,0 +1,534 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"review-assignment/internal/lib/businesstime"
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/models"
+	"review-assignment/internal/selector"
+)
+
+// Memory — реализация Repository в памяти процесса для локального запуска и тестов без БД.
+// Данные теряются при перезапуске.
+//
+// Каждая изменяющая операция работает под общей блокировкой с копией состояния и
+// подменяет им текущее только при успехе: так ошибка на середине операции откатывает
+// все её изменения, как откат транзакции в Storage.
+type Memory struct {
+	mu       sync.RWMutex
+	state    *memState
+	rng      selector.Rand
+	schedule businesstime.Schedule
+}
+
+type memState struct {
+	teams      map[string]models.TeamPolicy
+	users      map[string]models.User
+	prs        map[string]models.PullRequest
+	reviewers  map[string][]memReviewer
+	ooo        []models.OutOfOffice
+	prEvents   []models.PREvent
+	slaEvents  []models.SLAEvent
+	audit      []models.AuditEntry
+	webhooks   []models.WebhookSubscription
+	outbox     map[int64]models.WebhookDelivery
+	accounts   map[memAccountKey]models.ExternalAccount
+	deliveries map[memDeliveryKey]memDelivery
+	seq        memSequences
+}
+
+// memReviewer — строка pr_reviewers. Назначения PR хранятся в порядке assigned_at.
+type memReviewer struct {
+	reviewerID     string
+	assignedAt     time.Time
+	fromFallback   bool
+	state          models.ReviewState
+	stateUpdatedAt *time.Time
+	escalatedAt    *time.Time
+}
+
+type memAccountKey struct {
+	provider models.IntegrationProvider
+	login    string
+}
+
+type memDeliveryKey struct {
+	provider   models.IntegrationProvider
+	deliveryID string
+}
+
+// memDelivery — входящая доставка: захвачена до leaseUntil или уже обработана
+type memDelivery struct {
+	done       bool
+	leaseUntil time.Time
+}
+
+type memSequences struct {
+	ooo      int64
+	prEvent  int64
+	slaEvent int64
+	audit    int64
+	webhook  int64
+	delivery int64
+}
+
+func NewMemory(schedule businesstime.Schedule) *Memory {
+	return &Memory{
+		state: &memState{
+			teams:      make(map[string]models.TeamPolicy),
+			users:      make(map[string]models.User),
+			prs:        make(map[string]models.PullRequest),
+			reviewers:  make(map[string][]memReviewer),
+			outbox:     make(map[int64]models.WebhookDelivery),
+			accounts:   make(map[memAccountKey]models.ExternalAccount),
+			deliveries: make(map[memDeliveryKey]memDelivery),
+		},
+		rng:      selector.SafeRand(),
+		schedule: schedule,
+	}
+}
+
+// clone копирует изменяемые части состояния. Журналы (события, аудит) только дописываются,
+// поэтому копия делит с оригиналом их массивы: дописанное в копию не видно по длине оригинала.
+func (st *memState) clone() *memState {
+	next := *st
+	next.teams = maps.Clone(st.teams)
+	next.users = maps.Clone(st.users)
+	next.prs = maps.Clone(st.prs)
+	next.reviewers = make(map[string][]memReviewer, len(st.reviewers))
+	for prID, rows := range st.reviewers {
+		next.reviewers[prID] = slices.Clone(rows)
+	}
+	next.ooo = slices.Clone(st.ooo)
+	next.webhooks = slices.Clone(st.webhooks)
+	next.outbox = maps.Clone(st.outbox)
+	next.accounts = maps.Clone(st.accounts)
+	next.deliveries = maps.Clone(st.deliveries)
+	return &next
+}
+
+// update выполняет fn над копией состояния и фиксирует её, только если fn вернула nil
+func (m *Memory) update(ctx context.Context, fn func(st *memState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := m.state.clone()
+	if err := fn(next); err != nil {
+		return err
+	}
+	m.state = next
+	return nil
+}
+
+// view выполняет fn над текущим состоянием; fn не должна его изменять
+func (m *Memory) view(ctx context.Context, fn func(st *memState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return fn(m.state)
+}
+
+// TEAM METHODS
+
+func (m *Memory) CreateTeam(ctx context.Context, team models.Team, actor string) error {
+	const op = "storage.Memory.CreateTeam"
+
+	if team.Strategy == "" {
+		team.Strategy = models.StrategyRandom
+	}
+	if !selector.Valid(team.Strategy) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidStrategy)
+	}
+	if !validWeights(team.Members) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidWeight)
+	}
+
+	err := m.update(ctx, func(st *memState) error {
+		if _, ok := st.teams[team.Name]; ok {
+			return ErrTeamExists
+		}
+		st.teams[team.Name] = models.TeamPolicy{
+			TeamName:      team.Name,
+			MaxReviewers:  2,
+			Strategy:      team.Strategy,
+			FallbackTeams: []string{},
+			SLAAction:     models.SLAActionEscalate,
+		}
+
+		// участники могли состоять в других командах: их прежнее состояние попадает в аудит
+		var before interface{}
+		if movedUsers := st.existingUsers(team.Members); len(movedUsers) > 0 {
+			before = movedUsers
+		}
+
+		for _, member := range team.Members {
+			weight := 1
+			if member.Weight != nil {
+				weight = *member.Weight
+			} else if existing, ok := st.users[member.ID]; ok {
+				weight = *existing.Weight
+			}
+			st.users[member.ID] = models.User{
+				ID:       member.ID,
+				Username: member.Username,
+				TeamName: team.Name,
+				IsActive: member.IsActive,
+				Weight:   &weight,
+			}
+		}
+
+		return st.recordAudit(actor, models.AuditCreateTeam, team.Name, before, team)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Memory) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
+	const op = "storage.Memory.GetTeam"
+
+	var team *models.Team
+	err := m.view(ctx, func(st *memState) error {
+		policy, ok := st.teams[teamName]
+		if !ok {
+			return ErrTeamNotFound
+		}
+
+		var members []models.User
+		for _, user := range st.teamMembers(teamName) {
+			members = append(members, models.User{
+				ID:       user.ID,
+				Username: user.Username,
+				IsActive: user.IsActive,
+				Weight:   user.Weight,
+			})
+		}
+
+		team = &models.Team{
+			Name:     teamName,
+			Strategy: policy.Strategy,
+			Members:  members,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return team, nil
+}
+
+func (m *Memory) GetTeamPolicy(ctx context.Context, teamName string) (*models.TeamPolicy, error) {
+	const op = "storage.Memory.GetTeamPolicy"
+
+	var policy *models.TeamPolicy
+	err := m.view(ctx, func(st *memState) error {
+		var err error
+		policy, err = st.teamPolicy(teamName)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return policy, nil
+}
+
+func (m *Memory) SetTeamPolicy(ctx context.Context, req models.SetTeamPolicyRequest, actor string) (*models.TeamPolicy, error) {
+	const op = "storage.Memory.SetTeamPolicy"
+
+	var policy *models.TeamPolicy
+	err := m.update(ctx, func(st *memState) error {
+		var err error
+		policy, err = st.teamPolicy(req.TeamName)
+		if err != nil {
+			return err
+		}
+		before := *policy
+
+		if err := applyPolicyRequest(policy, req); err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool, len(policy.FallbackTeams))
+		for _, team := range policy.FallbackTeams {
+			if _, exists := st.teams[team]; team == policy.TeamName || seen[team] || !exists {
+				return ErrInvalidPolicy
+			}
+			seen[team] = true
+		}
+
+		stored := *policy
+		stored.FallbackTeams = slices.Clone(policy.FallbackTeams)
+		st.teams[policy.TeamName] = stored
+		return st.recordAudit(actor, models.AuditSetTeamPolicy, policy.TeamName, before, policy)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return policy, nil
+}
+
+func (m *Memory) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string, actor string) (*models.TeamDeactivationResult, error) {
+	const op = "storage.Memory.DeactivateTeamUsers"
+
+	var result *models.TeamDeactivationResult
+	err := m.update(ctx, func(st *memState) error {
+		if _, ok := st.teams[teamName]; !ok {
+			return ErrTeamNotFound
+		}
+
+		userIDs = unique(userIDs)
+		before := []models.User{}
+		deactivated := []string{}
+		for _, user := range st.teamMembers(teamName) {
+			if len(userIDs) > 0 && !slices.Contains(userIDs, user.ID) {
+				continue
+			}
+			before = append(before, models.User{ID: user.ID, Username: user.Username, TeamName: user.TeamName, IsActive: user.IsActive})
+			user.IsActive = false
+			st.users[user.ID] = user
+			deactivated = append(deactivated, user.ID)
+		}
+
+		if len(userIDs) > 0 && len(deactivated) != len(userIDs) {
+			return ErrMemberNotFound
+		}
+
+		result = &models.TeamDeactivationResult{
+			TeamName:    teamName,
+			Deactivated: deactivated,
+			ReassignmentReport: models.ReassignmentReport{
+				Reassigned:    []models.Reassignment{},
+				NotReassigned: []models.ReassignmentFailure{},
+			},
+		}
+		if err := m.reassignOpenReviews(st, deactivated, models.ReasonUserDeactivated, &result.ReassignmentReport); err != nil {
+			return err
+		}
+
+		return st.recordAudit(actor, models.AuditDeactivateTeamUsers, teamName, before, result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceDeactivated, &result.ReassignmentReport)
+
+	return result, nil
+}
+
+// USER METHODS
+
+func (m *Memory) SetUserActive(ctx context.Context, userID string, isActive bool, actor string) (*models.User, *models.ReassignmentReport, error) {
+	const op = "storage.Memory.SetUserActive"
+
+	var user models.User
+	report := &models.ReassignmentReport{
+		Reassigned:    []models.Reassignment{},
+		NotReassigned: []models.ReassignmentFailure{},
+	}
+	err := m.update(ctx, func(st *memState) error {
+		stored, ok := st.users[userID]
+		if !ok {
+			return ErrUserNotFound
+		}
+		before := models.User{ID: stored.ID, Username: stored.Username, TeamName: stored.TeamName, IsActive: stored.IsActive}
+
+		stored.IsActive = isActive
+		st.users[userID] = stored
+		user = models.User{ID: stored.ID, Username: stored.Username, TeamName: stored.TeamName, IsActive: stored.IsActive}
+
+		if !isActive {
+			if err := m.reassignOpenReviews(st, []string{userID}, models.ReasonUserDeactivated, report); err != nil {
+				return err
+			}
+		}
+
+		after := struct {
+			*models.User
+			*models.ReassignmentReport
+		}{&user, report}
+		return st.recordAudit(actor, models.AuditSetUserActive, userID, before, after)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceDeactivated, report)
+
+	return &user, report, nil
+}
+
+func (m *Memory) GetUserReviews(ctx context.Context, userID string) ([]models.PullRequest, error) {
+	const op = "storage.Memory.GetUserReviews"
+
+	var prs []models.PullRequest
+	err := m.view(ctx, func(st *memState) error {
+		if _, ok := st.users[userID]; !ok {
+			return ErrUserNotFound
+		}
+
+		for prID, rows := range st.reviewers {
+			if !slices.ContainsFunc(rows, func(r memReviewer) bool { return r.reviewerID == userID }) {
+				continue
+			}
+			pr := st.prs[prID]
+			prs = append(prs, models.PullRequest{
+				ID:        pr.ID,
+				Name:      pr.Name,
+				AuthorID:  pr.AuthorID,
+				Status:    pr.Status,
+				CreatedAt: pr.CreatedAt,
+				MergedAt:  pr.MergedAt,
+			})
+		}
+		sort.SliceStable(prs, func(i, j int) bool {
+			return prs[i].CreatedAt.After(prs[j].CreatedAt)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return prs, nil
+}
+
+// OUT OF OFFICE METHODS
+
+func (m *Memory) ListOutOfOffice(ctx context.Context, userID string) ([]models.OutOfOffice, error) {
+	const op = "storage.Memory.ListOutOfOffice"
+
+	periods := []models.OutOfOffice{}
+	err := m.view(ctx, func(st *memState) error {
+		if _, ok := st.users[userID]; !ok {
+			return ErrUserNotFound
+		}
+
+		for _, period := range st.ooo {
+			if period.UserID == userID {
+				periods = append(periods, period)
+			}
+		}
+		sort.SliceStable(periods, func(i, j int) bool {
+			return periods[i].StartsAt.Before(periods[j].StartsAt)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return periods, nil
+}
+
+func (m *Memory) AddOutOfOffice(ctx context.Context, req models.AddOutOfOfficeRequest) (*models.OutOfOffice, error) {
+	const op = "storage.Memory.AddOutOfOffice"
+
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+
+	period := models.OutOfOffice{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	}
+	err := m.update(ctx, func(st *memState) error {
+		if _, ok := st.users[req.UserID]; !ok {
+			return ErrUserNotFound
+		}
+
+		st.seq.ooo++
+		period.ID = st.seq.ooo
+		st.ooo = append(st.ooo, period)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &period, nil
+}
+
+func (m *Memory) RemoveOutOfOffice(ctx context.Context, userID string, id int64) error {
+	const op = "storage.Memory.RemoveOutOfOffice"
+
+	err := m.update(ctx, func(st *memState) error {
+		i := slices.IndexFunc(st.ooo, func(p models.OutOfOffice) bool {
+			return p.ID == id && p.UserID == userID
+		})
+		if i < 0 {
+			return ErrPeriodNotFound
+		}
+		st.ooo = slices.Delete(st.ooo, i, i+1)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// HELPER METHODS
+
+func (st *memState) teamPolicy(teamName string) (*models.TeamPolicy, error) {
+	stored, ok := st.teams[teamName]
+	if !ok {
+		return nil, ErrTeamNotFound
+	}
+
+	policy := stored
+	policy.FallbackTeams = slices.Clone(stored.FallbackTeams)
+	if policy.FallbackTeams == nil {
+		policy.FallbackTeams = []string{}
+	}
+	return &policy, nil
+}
+
+// teamMembers возвращает участников команды, отсортированных по user_id
+func (st *memState) teamMembers(teamName string) []models.User {
+	var members []models.User
+	for _, user := range st.users {
+		if user.TeamName == teamName {
+			members = append(members, user)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ID < members[j].ID
+	})
+	return members
+}
+
+// existingUsers возвращает уже существующих пользователей из переданного списка
+func (st *memState) existingUsers(members []models.User) []models.User {
+	var users []models.User
+	for _, member := range members {
+		if user, ok := st.users[member.ID]; ok && !slices.ContainsFunc(users, func(u models.User) bool { return u.ID == user.ID }) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users
+}
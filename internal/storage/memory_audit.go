@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"review-assignment/internal/models"
+)
+
+// AUDIT METHODS
+
+func (m *Memory) ListAudit(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error) {
+	const op = "storage.Memory.ListAudit"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	entries := []models.AuditEntry{}
+	err := m.queryAudit(ctx, filter, limit+1, func(entry models.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := &models.AuditPage{}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = strconv.FormatInt(entries[len(entries)-1].ID, 10)
+	}
+	page.Entries = entries
+
+	return page, nil
+}
+
+func (m *Memory) ExportAudit(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error {
+	const op = "storage.Memory.ExportAudit"
+
+	if err := m.queryAudit(ctx, filter, 0, fn); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// queryAudit отдаёт записи от новых к старым. Журнал только дописывается, поэтому
+// fn вызывается уже без блокировки, по снимку журнала на момент запроса.
+func (m *Memory) queryAudit(ctx context.Context, filter models.AuditFilter, limit int, fn func(models.AuditEntry) error) error {
+	var cursor int64
+	if filter.Cursor != "" {
+		id, err := strconv.ParseInt(filter.Cursor, 10, 64)
+		if err != nil {
+			return ErrInvalidCursor
+		}
+		cursor = id
+	}
+
+	var log []models.AuditEntry
+	err := m.view(ctx, func(st *memState) error {
+		log = st.audit
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for i := len(log) - 1; i >= 0; i-- {
+		if limit > 0 && sent == limit {
+			break
+		}
+
+		entry := log[i]
+		if filter.Cursor != "" && entry.ID >= cursor {
+			continue
+		}
+		if filter.Actor != "" && entry.Actor != filter.Actor {
+			continue
+		}
+		if len(filter.Operations) > 0 && !slices.Contains(filter.Operations, entry.Operation) {
+			continue
+		}
+		if filter.EntityID != "" && entry.EntityID != filter.EntityID {
+			continue
+		}
+		if filter.From != nil && entry.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !entry.CreatedAt.Before(*filter.To) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+		sent++
+	}
+
+	return nil
+}
+
+// recordAudit дописывает запись в журнал аудита; before и after сериализуются в JSON, nil остаётся пустым
+func (st *memState) recordAudit(actor string, operation models.AuditOperation, entityID string, before, after interface{}) error {
+	entry := models.AuditEntry{
+		Actor:     actor,
+		Operation: operation,
+		EntityID:  entityID,
+		CreatedAt: time.Now(),
+	}
+
+	var err error
+	if entry.Before, err = auditJSON(before); err != nil {
+		return err
+	}
+	if entry.After, err = auditJSON(after); err != nil {
+		return err
+	}
+
+	st.seq.audit++
+	entry.ID = st.seq.audit
+	st.audit = append(st.audit, entry)
+	return nil
+}
+
+func auditJSON(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
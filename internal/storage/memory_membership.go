@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/models"
+)
+
+func (m *Memory) RemoveTeamMember(ctx context.Context, teamName, userID, actor string) (*models.TeamMemberChange, error) {
+	const op = "storage.Memory.RemoveTeamMember"
+
+	var result *models.TeamMemberChange
+	err := m.update(ctx, func(st *memState) error {
+		stored, ok := st.users[userID]
+		if !ok || stored.TeamName != teamName {
+			return ErrMemberNotFound
+		}
+		before := models.User{ID: stored.ID, Username: stored.Username, TeamName: stored.TeamName, IsActive: stored.IsActive}
+
+		for _, pr := range st.prs {
+			if pr.AuthorID == userID && (pr.Status == models.StatusOpen || pr.Status == models.StatusDraft) {
+				return ErrMemberHasOpenPRs
+			}
+		}
+
+		result = newTeamMemberChange(teamName)
+		if err := m.reassignOpenReviews(st, []string{userID}, models.ReasonMemberRemoved, &result.ReassignmentReport); err != nil {
+			return err
+		}
+
+		stored.TeamName = ""
+		stored.IsActive = false
+		st.users[userID] = stored
+		result.User = models.User{ID: stored.ID, Username: stored.Username, TeamName: stored.TeamName, IsActive: stored.IsActive}
+
+		return st.recordAudit(actor, models.AuditRemoveTeamMember, userID, before, result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceTeamChange, &result.ReassignmentReport)
+
+	return result, nil
+}
+
+func (m *Memory) MoveTeamMember(ctx context.Context, req models.MoveTeamMemberRequest, actor string) (*models.TeamMemberChange, error) {
+	const op = "storage.Memory.MoveTeamMember"
+
+	if req.OpenReviews != models.OpenReviewsKeep && req.OpenReviews != models.OpenReviewsReassign {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidOpenReviews)
+	}
+
+	var result *models.TeamMemberChange
+	err := m.update(ctx, func(st *memState) error {
+		stored, ok := st.users[req.UserID]
+		if !ok {
+			return ErrUserNotFound
+		}
+		if stored.TeamName == req.ToTeam {
+			return ErrSameTeam
+		}
+		if _, ok := st.teams[req.ToTeam]; !ok {
+			return ErrTeamNotFound
+		}
+		before := models.User{ID: stored.ID, Username: stored.Username, TeamName: stored.TeamName, IsActive: stored.IsActive}
+
+		result = newTeamMemberChange(stored.TeamName)
+		if req.OpenReviews == models.OpenReviewsReassign {
+			if err := m.reassignOpenReviews(st, []string{req.UserID}, models.ReasonMemberMoved, &result.ReassignmentReport); err != nil {
+				return err
+			}
+		}
+
+		stored.TeamName = req.ToTeam
+		st.users[req.UserID] = stored
+		result.User = models.User{ID: stored.ID, Username: stored.Username, TeamName: stored.TeamName, IsActive: stored.IsActive}
+
+		return st.recordAudit(actor, models.AuditMoveTeamMember, req.UserID, before, result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceTeamChange, &result.ReassignmentReport)
+
+	return result, nil
+}
+
+// DeleteTeam повторяет каскадное удаление Storage.DeleteTeam: резервные команды и подписки
+func (m *Memory) DeleteTeam(ctx context.Context, teamName, actor string) error {
+	const op = "storage.Memory.DeleteTeam"
+
+	err := m.update(ctx, func(st *memState) error {
+		before, err := st.teamPolicy(teamName)
+		if err != nil {
+			return err
+		}
+		if len(st.teamMembers(teamName)) > 0 {
+			return ErrTeamNotEmpty
+		}
+
+		delete(st.teams, teamName)
+		for name, policy := range st.teams {
+			if slices.Contains(policy.FallbackTeams, teamName) {
+				policy.FallbackTeams = slices.DeleteFunc(slices.Clone(policy.FallbackTeams), func(team string) bool { return team == teamName })
+				st.teams[name] = policy
+			}
+		}
+
+		var removed []int64
+		st.webhooks = slices.DeleteFunc(st.webhooks, func(sub models.WebhookSubscription) bool {
+			if sub.TeamName == teamName {
+				removed = append(removed, sub.ID)
+				return true
+			}
+			return false
+		})
+		for deliveryID, d := range st.outbox {
+			if slices.Contains(removed, d.SubscriptionID) {
+				delete(st.outbox, deliveryID)
+			}
+		}
+
+		return st.recordAudit(actor, models.AuditDeleteTeam, teamName, before, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
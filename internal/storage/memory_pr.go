@@ -0,0 +1,942 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/models"
+	"review-assignment/internal/selector"
+)
+
+// PR METHODS
+
+func (m *Memory) GetPR(ctx context.Context, prID string) (*models.PRDetails, error) {
+	const op = "storage.Memory.GetPR"
+
+	var details *models.PRDetails
+	err := m.view(ctx, func(st *memState) error {
+		pr, err := st.prWithReviewers(prID)
+		if err != nil {
+			return err
+		}
+
+		reviews := []models.Review{}
+		for _, row := range st.reviewers[prID] {
+			reviews = append(reviews, models.Review{
+				PRID:       prID,
+				ReviewerID: row.reviewerID,
+				State:      row.state,
+				UpdatedAt:  row.stateUpdatedAt,
+			})
+		}
+
+		timeline := []models.PREvent{}
+		for _, e := range st.prEvents {
+			if e.PRID == prID {
+				timeline = append(timeline, e)
+			}
+		}
+
+		details = &models.PRDetails{
+			PR:       pr,
+			Reviews:  reviews,
+			Timeline: timeline,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return details, nil
+}
+
+func (m *Memory) CreatePR(ctx context.Context, req models.CreatePRRequest, actor string) (*models.PullRequest, error) {
+	const op = "storage.Memory.CreatePR"
+
+	var pr *models.PullRequest
+	err := m.update(ctx, func(st *memState) error {
+		author, ok := st.users[req.AuthorID]
+		if !ok || author.TeamName == "" {
+			return ErrAuthorNotFound
+		}
+		if _, exists := st.prs[req.ID]; exists {
+			return ErrPRExists
+		}
+
+		pr = &models.PullRequest{
+			ID:                req.ID,
+			Name:              req.Name,
+			AuthorID:          req.AuthorID,
+			Status:            models.StatusOpen,
+			AssignedReviewers: []string{},
+			CreatedAt:         time.Now(),
+		}
+		if req.Draft {
+			pr.Status = models.StatusDraft
+		}
+		st.prs[pr.ID] = models.PullRequest{
+			ID:        pr.ID,
+			Name:      pr.Name,
+			AuthorID:  pr.AuthorID,
+			Status:    pr.Status,
+			CreatedAt: pr.CreatedAt,
+		}
+
+		if err := st.recordPREvents(models.PREvent{PRID: pr.ID, Type: models.PREventCreated}); err != nil {
+			return err
+		}
+
+		if pr.Status == models.StatusOpen {
+			if err := m.assignReviewers(st, pr, author.TeamName); err != nil {
+				return err
+			}
+		}
+
+		return st.recordAudit(actor, models.AuditCreatePR, pr.ID, nil, pr)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	metrics.PRsCreated.Inc()
+
+	return pr, nil
+}
+
+func (m *Memory) MergePR(ctx context.Context, prID string, actor string) (*models.PullRequest, error) {
+	const op = "storage.Memory.MergePR"
+
+	pr, err := m.mergePR(ctx, prID, actor, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pr, nil
+}
+
+func (m *Memory) MergeExternalPR(ctx context.Context, prID string, actor string) (*models.PullRequest, error) {
+	const op = "storage.Memory.MergeExternalPR"
+
+	pr, err := m.mergePR(ctx, prID, actor, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pr, nil
+}
+
+// mergePR повторяет Storage.mergePR
+func (m *Memory) mergePR(ctx context.Context, prID string, actor string, external bool) (*models.PullRequest, error) {
+	var pr *models.PullRequest
+	merged := false
+	err := m.update(ctx, func(st *memState) error {
+		var err error
+		pr, err = st.prWithReviewers(prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == models.StatusMerged {
+			return nil
+		}
+		if !external {
+			if !canTransition(pr.Status, models.StatusMerged) {
+				return ErrInvalidTransition
+			}
+			if err := st.checkApprovals(pr); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		stored := st.prs[prID]
+		stored.Status = models.StatusMerged
+		stored.MergedAt = &now
+		st.prs[prID] = stored
+
+		if err := st.recordPREvents(models.PREvent{PRID: prID, Type: models.PREventMerged}); err != nil {
+			return err
+		}
+
+		before := *pr
+		pr.Status = models.StatusMerged
+		pr.MergedAt = &now
+		merged = true
+		return st.recordAudit(actor, models.AuditMergePR, prID, before, pr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if merged {
+		metrics.PRsMerged.Inc()
+	}
+
+	return pr, nil
+}
+
+func (m *Memory) ReassignReviewer(ctx context.Context, req models.ReassignRequest, actor string) (*models.PullRequest, string, error) {
+	const op = "storage.Memory.ReassignReviewer"
+
+	var pr *models.PullRequest
+	var newReviewer string
+	err := m.update(ctx, func(st *memState) error {
+		var err error
+		pr, err = st.prWithReviewers(req.PRID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == models.StatusMerged {
+			return ErrPRMerged
+		}
+		if pr.Status != models.StatusOpen {
+			return ErrPRNotOpen
+		}
+
+		if !slices.Contains(pr.AssignedReviewers, req.OldReviewer) {
+			return ErrNotAssigned
+		}
+
+		before := *pr
+		newReviewer, err = m.replaceReviewer(st, pr, req.OldReviewer, models.ReasonManual)
+		if err != nil {
+			if errors.Is(err, ErrNoCandidate) {
+				metrics.NoCandidate.WithLabelValues(metrics.SourceManual).Inc()
+			}
+			return err
+		}
+
+		after := struct {
+			*models.PullRequest
+			ReplacedBy string `json:"replaced_by"`
+		}{pr, newReviewer}
+		return st.recordAudit(actor, models.AuditReassignReviewer, pr.ID, before, after)
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	metrics.Reassignments.WithLabelValues(metrics.SourceManual).Inc()
+
+	return pr, newReviewer, nil
+}
+
+// LIFECYCLE METHODS
+
+func (m *Memory) MarkReady(ctx context.Context, prID, actor string) (*models.PullRequest, error) {
+	const op = "storage.Memory.MarkReady"
+
+	pr, err := m.transitionPR(ctx, prID, models.StatusDraft, models.StatusOpen, actor)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return pr, nil
+}
+
+func (m *Memory) ReopenPR(ctx context.Context, prID, actor string) (*models.PullRequest, error) {
+	const op = "storage.Memory.ReopenPR"
+
+	pr, err := m.transitionPR(ctx, prID, models.StatusClosed, models.StatusOpen, actor)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return pr, nil
+}
+
+func (m *Memory) ClosePR(ctx context.Context, prID, actor string) (*models.PullRequest, error) {
+	const op = "storage.Memory.ClosePR"
+
+	pr, err := m.transitionPR(ctx, prID, "", models.StatusClosed, actor)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return pr, nil
+}
+
+// transitionPR повторяет Storage.transitionPR
+func (m *Memory) transitionPR(ctx context.Context, prID string, from, to models.PRStatus, actor string) (*models.PullRequest, error) {
+	var pr *models.PullRequest
+	err := m.update(ctx, func(st *memState) error {
+		var err error
+		pr, err = st.prWithReviewers(prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == to && from == "" {
+			return nil
+		}
+		if (from != "" && pr.Status != from) || !canTransition(pr.Status, to) {
+			return ErrInvalidTransition
+		}
+
+		eventType, operation := models.PREventClosed, models.AuditClosePR
+		if to == models.StatusOpen {
+			eventType, operation = models.PREventReady, models.AuditMarkReady
+			if pr.Status == models.StatusClosed {
+				eventType, operation = models.PREventReopened, models.AuditReopenPR
+			}
+		}
+		if err := st.recordPREvents(models.PREvent{PRID: prID, Type: eventType}); err != nil {
+			return err
+		}
+
+		before := *pr
+		stored := st.prs[prID]
+		stored.Status = to
+		switch to {
+		case models.StatusClosed:
+			var unassigned []models.PREvent
+			for _, row := range st.reviewers[prID] {
+				unassigned = append(unassigned, models.PREvent{
+					PRID:       prID,
+					Type:       models.PREventUnassigned,
+					ReviewerID: row.reviewerID,
+					State:      row.state,
+					Reason:     models.ReasonPRClosed,
+				})
+			}
+			if err := st.recordPREvents(unassigned...); err != nil {
+				return err
+			}
+			delete(st.reviewers, prID)
+
+			now := time.Now()
+			stored.ClosedAt = &now
+			st.prs[prID] = stored
+
+			pr.ClosedAt = &now
+			pr.AssignedReviewers = []string{}
+			pr.FallbackReviewers = nil
+
+		case models.StatusOpen:
+			stored.ClosedAt = nil
+			st.prs[prID] = stored
+
+			pr.ClosedAt = nil
+			if err := m.assignReviewers(st, pr, st.users[pr.AuthorID].TeamName); err != nil {
+				return err
+			}
+		}
+
+		pr.Status = to
+		return st.recordAudit(actor, operation, prID, before, pr)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// REVIEW METHODS
+
+func (m *Memory) SubmitReview(ctx context.Context, req models.SubmitReviewRequest, actor string) (*models.Review, error) {
+	const op = "storage.Memory.SubmitReview"
+
+	switch req.State {
+	case models.ReviewApproved, models.ReviewChangesRequested, models.ReviewDismissed:
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidReviewState)
+	}
+
+	review := models.Review{
+		PRID:       req.PRID,
+		ReviewerID: req.ReviewerID,
+		State:      req.State,
+	}
+	err := m.update(ctx, func(st *memState) error {
+		pr, ok := st.prs[req.PRID]
+		if !ok {
+			return ErrPRNotFound
+		}
+		if pr.Status == models.StatusMerged {
+			return ErrPRMerged
+		}
+		if pr.Status != models.StatusOpen {
+			return ErrPRNotOpen
+		}
+
+		row := st.reviewer(req.PRID, req.ReviewerID)
+		if row == nil {
+			return ErrNotAssigned
+		}
+		before := models.Review{PRID: req.PRID, ReviewerID: req.ReviewerID, State: row.state, UpdatedAt: row.stateUpdatedAt}
+
+		now := time.Now()
+		row.state = req.State
+		row.stateUpdatedAt = &now
+		review.UpdatedAt = &now
+
+		err := st.recordPREvents(models.PREvent{
+			PRID:       req.PRID,
+			Type:       models.PREventReviewed,
+			ReviewerID: req.ReviewerID,
+			State:      req.State,
+		})
+		if err != nil {
+			return err
+		}
+
+		return st.recordAudit(actor, models.AuditSubmitReview, req.PRID, before, review)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &review, nil
+}
+
+// LIST METHODS
+
+func (m *Memory) ListPRs(ctx context.Context, filter models.PRListFilter) (*models.PRListPage, error) {
+	const op = "storage.Memory.ListPRs"
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	var cursorAt time.Time
+	var cursorID string
+	if filter.Cursor != "" {
+		var err error
+		cursorAt, cursorID, err = decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidCursor)
+		}
+	}
+
+	page := &models.PRListPage{}
+	err := m.view(ctx, func(st *memState) error {
+		prs := []models.PullRequest{}
+		for _, pr := range st.prs {
+			if filter.Cursor != "" && !pr.CreatedAt.Before(cursorAt) && !(pr.CreatedAt.Equal(cursorAt) && pr.ID < cursorID) {
+				continue
+			}
+			if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, pr.Status) {
+				continue
+			}
+			if filter.AuthorID != "" && pr.AuthorID != filter.AuthorID {
+				continue
+			}
+			if filter.ReviewerID != "" && st.reviewer(pr.ID, filter.ReviewerID) == nil {
+				continue
+			}
+			if filter.TeamName != "" && st.users[pr.AuthorID].TeamName != filter.TeamName {
+				continue
+			}
+			if filter.NameContains != "" && !strings.Contains(strings.ToLower(pr.Name), strings.ToLower(filter.NameContains)) {
+				continue
+			}
+			if filter.CreatedFrom != nil && pr.CreatedAt.Before(*filter.CreatedFrom) {
+				continue
+			}
+			if filter.CreatedTo != nil && !pr.CreatedAt.Before(*filter.CreatedTo) {
+				continue
+			}
+			if filter.MergedFrom != nil && (pr.MergedAt == nil || pr.MergedAt.Before(*filter.MergedFrom)) {
+				continue
+			}
+			if filter.MergedTo != nil && (pr.MergedAt == nil || !pr.MergedAt.Before(*filter.MergedTo)) {
+				continue
+			}
+			prs = append(prs, pr)
+		}
+
+		sort.Slice(prs, func(i, j int) bool {
+			if !prs[i].CreatedAt.Equal(prs[j].CreatedAt) {
+				return prs[i].CreatedAt.After(prs[j].CreatedAt)
+			}
+			return prs[i].ID > prs[j].ID
+		})
+
+		if len(prs) > limit {
+			prs = prs[:limit]
+			last := prs[len(prs)-1]
+			page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+		}
+
+		for i := range prs {
+			prs[i].AssignedReviewers = []string{}
+			for _, row := range st.reviewers[prs[i].ID] {
+				prs[i].AssignedReviewers = append(prs[i].AssignedReviewers, row.reviewerID)
+				if row.fromFallback {
+					prs[i].FallbackReviewers = append(prs[i].FallbackReviewers, row.reviewerID)
+				}
+			}
+		}
+		page.PullRequests = prs
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return page, nil
+}
+
+// STATS METHODS
+
+func (m *Memory) CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	const op = "storage.Memory.CountOpenReviewsByTeam"
+
+	counts := make(map[string]int)
+	err := m.view(ctx, func(st *memState) error {
+		for team := range st.teams {
+			counts[team] = 0
+		}
+		for prID, rows := range st.reviewers {
+			if st.prs[prID].Status != models.StatusOpen {
+				continue
+			}
+			for _, row := range rows {
+				// ревьюверы, удалённые из команды, ни к одной команде не относятся
+				if team := st.users[row.reviewerID].TeamName; team != "" {
+					counts[team]++
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return counts, nil
+}
+
+// ASSIGNMENT HELPERS
+
+// assignReviewers подбирает и сохраняет ревьюверов для PR по политике команды автора
+func (m *Memory) assignReviewers(st *memState, pr *models.PullRequest, authorTeam string) error {
+	policy, err := st.teamPolicy(authorTeam)
+	if err != nil {
+		return err
+	}
+
+	reviewers, fallbackReviewers, err := m.pickReviewers(st, policy, authorTeam, []string{pr.AuthorID})
+	if err != nil {
+		return err
+	}
+	if len(reviewers) < policy.MinReviewers {
+		return ErrNotEnoughReviewers
+	}
+
+	now := time.Now()
+	events := make([]models.PREvent, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		st.reviewers[pr.ID] = append(st.reviewers[pr.ID], memReviewer{
+			reviewerID:   reviewer,
+			assignedAt:   now,
+			fromFallback: slices.Contains(fallbackReviewers, reviewer),
+			state:        models.ReviewPending,
+		})
+		events = append(events, models.PREvent{PRID: pr.ID, Type: models.PREventAssigned, ReviewerID: reviewer})
+	}
+	if err := st.recordPREvents(events...); err != nil {
+		return err
+	}
+
+	stored := st.prs[pr.ID]
+	stored.Strategy = policy.Strategy
+	st.prs[pr.ID] = stored
+
+	pr.AssignedReviewers = reviewers
+	pr.FallbackReviewers = fallbackReviewers
+	pr.Strategy = policy.Strategy
+	return nil
+}
+
+// pickReviewers выбирает до policy.MaxReviewers ревьюверов из команды автора,
+// добирая недостающих из резервных команд, если политика это разрешает
+func (m *Memory) pickReviewers(st *memState, policy *models.TeamPolicy, authorTeam string, exclude []string) ([]string, []string, error) {
+	reviewers, err := m.selectReviewers(policy.Strategy, st.findCandidates(authorTeam, exclude), policy.MaxReviewers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fallbackReviewers []string
+	if len(reviewers) < policy.MaxReviewers && policy.AllowCrossTeam {
+		skip := append(append([]string{}, exclude...), reviewers...)
+		fallbackReviewers, err = m.selectFromFallbackTeams(st, policy, skip, policy.MaxReviewers-len(reviewers))
+		if err != nil {
+			return nil, nil, err
+		}
+		reviewers = append(reviewers, fallbackReviewers...)
+	}
+
+	return reviewers, fallbackReviewers, nil
+}
+
+// replaceReviewer повторяет Storage.replaceReviewer: pr изменяется на месте
+func (m *Memory) replaceReviewer(st *memState, pr *models.PullRequest, oldReviewer, reason string) (string, error) {
+	oldReviewerTeam := st.users[oldReviewer].TeamName
+	authorTeam := st.users[pr.AuthorID].TeamName
+	if oldReviewerTeam == "" {
+		oldReviewerTeam = authorTeam
+	}
+
+	policy, err := st.teamPolicy(authorTeam)
+	if err != nil {
+		return "", err
+	}
+
+	exclude := append([]string{pr.AuthorID, oldReviewer}, pr.AssignedReviewers...)
+	selected, err := m.selectReviewers(policy.Strategy, st.findCandidates(oldReviewerTeam, exclude), 1)
+	if err != nil {
+		return "", err
+	}
+	fromFallback := oldReviewerTeam != authorTeam
+
+	if len(selected) == 0 && policy.AllowCrossTeam {
+		exclude := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
+		selected, err = m.selectFromFallbackTeams(st, policy, exclude, 1)
+		if err != nil {
+			return "", err
+		}
+		fromFallback = true
+	}
+
+	if len(selected) == 0 {
+		return "", ErrNoCandidate
+	}
+	newReviewer := selected[0]
+
+	st.removeReviewer(pr.ID, oldReviewer)
+	st.reviewers[pr.ID] = append(st.reviewers[pr.ID], memReviewer{
+		reviewerID:   newReviewer,
+		assignedAt:   time.Now(),
+		fromFallback: fromFallback,
+		state:        models.ReviewPending,
+	})
+
+	stored := st.prs[pr.ID]
+	stored.Strategy = policy.Strategy
+	st.prs[pr.ID] = stored
+
+	err = st.recordPREvents(models.PREvent{
+		PRID:          pr.ID,
+		Type:          models.PREventReassigned,
+		OldReviewerID: oldReviewer,
+		NewReviewerID: newReviewer,
+		Reason:        reason,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// новые срезы, а не изменение на месте: вызывающий хранит копию pr для аудита
+	assigned := make([]string, len(pr.AssignedReviewers))
+	for i, reviewer := range pr.AssignedReviewers {
+		assigned[i] = reviewer
+		if reviewer == oldReviewer {
+			assigned[i] = newReviewer
+		}
+	}
+	pr.AssignedReviewers = assigned
+	pr.FallbackReviewers = slices.DeleteFunc(slices.Clone(pr.FallbackReviewers), func(r string) bool { return r == oldReviewer })
+	if fromFallback {
+		pr.FallbackReviewers = append(pr.FallbackReviewers, newReviewer)
+	}
+	pr.Strategy = policy.Strategy
+
+	return newReviewer, nil
+}
+
+// selectFromFallbackTeams добирает до n ревьюверов из резервных команд в порядке их приоритета
+func (m *Memory) selectFromFallbackTeams(st *memState, policy *models.TeamPolicy, exclude []string, n int) ([]string, error) {
+	selected := []string{}
+
+	for _, team := range policy.FallbackTeams {
+		if len(selected) >= n {
+			break
+		}
+
+		skip := append(append([]string{}, exclude...), selected...)
+		picked, err := m.selectReviewers(policy.Strategy, st.findCandidates(team, skip), n-len(selected))
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, picked...)
+	}
+
+	return selected, nil
+}
+
+func (m *Memory) selectReviewers(strategy models.AssignmentStrategy, candidates []selector.Candidate, max int) ([]string, error) {
+	sel, err := selector.New(strategy, m.rng)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(candidates, max), nil
+}
+
+// reassignOpenReviews повторяет Storage.reassignOpenReviews
+func (m *Memory) reassignOpenReviews(st *memState, userIDs []string, reason string, report *models.ReassignmentReport) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	type assignment struct {
+		pr         models.PullRequest
+		reviewerID string
+	}
+	var assignments []assignment
+	for prID, rows := range st.reviewers {
+		pr := st.prs[prID]
+		if pr.Status != models.StatusOpen {
+			continue
+		}
+		for _, row := range rows {
+			if slices.Contains(userIDs, row.reviewerID) {
+				assignments = append(assignments, assignment{pr: pr, reviewerID: row.reviewerID})
+			}
+		}
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		a, b := assignments[i], assignments[j]
+		if !a.pr.CreatedAt.Equal(b.pr.CreatedAt) {
+			return a.pr.CreatedAt.Before(b.pr.CreatedAt)
+		}
+		if a.pr.ID != b.pr.ID {
+			return a.pr.ID < b.pr.ID
+		}
+		return a.reviewerID < b.reviewerID
+	})
+
+	// нагрузка кандидатов пересчитывается по мере назначения, как в Storage
+	pools := make(map[string][]selector.Candidate)
+	poolIndex := make(map[string]int)
+	var available []selector.Candidate
+	pick := func(team string, strategy models.AssignmentStrategy, exclude []string) (string, error) {
+		pool, ok := pools[team]
+		if !ok {
+			pool = st.findCandidates(team, nil)
+			pools[team] = pool
+			for i, c := range pool {
+				poolIndex[c.UserID] = i
+			}
+		}
+
+		available = available[:0]
+		for _, c := range pool {
+			if !slices.Contains(exclude, c.UserID) {
+				available = append(available, c)
+			}
+		}
+
+		selected, err := m.selectReviewers(strategy, available, 1)
+		if err != nil || len(selected) == 0 {
+			return "", err
+		}
+
+		now := time.Now()
+		chosen := &pool[poolIndex[selected[0]]]
+		chosen.OpenReviews++
+		chosen.LastAssignedAt = &now
+		return selected[0], nil
+	}
+
+	for _, a := range assignments {
+		reviewerTeam := st.users[a.reviewerID].TeamName
+		authorTeam := st.users[a.pr.AuthorID].TeamName
+		policy, err := st.teamPolicy(authorTeam)
+		if err != nil {
+			return err
+		}
+
+		exclude := []string{a.pr.AuthorID}
+		for _, row := range st.reviewers[a.pr.ID] {
+			exclude = append(exclude, row.reviewerID)
+		}
+
+		newReviewer, err := pick(reviewerTeam, policy.Strategy, exclude)
+		if err != nil {
+			return err
+		}
+		fromFallback := reviewerTeam != authorTeam
+
+		if newReviewer == "" && policy.AllowCrossTeam {
+			for _, team := range policy.FallbackTeams {
+				newReviewer, err = pick(team, policy.Strategy, exclude)
+				if err != nil {
+					return err
+				}
+				if newReviewer != "" {
+					fromFallback = true
+					break
+				}
+			}
+		}
+
+		if newReviewer == "" {
+			report.NotReassigned = append(report.NotReassigned, models.ReassignmentFailure{
+				PRID:        a.pr.ID,
+				OldReviewer: a.reviewerID,
+				Reason:      ErrNoCandidate.Code,
+			})
+			continue
+		}
+
+		st.removeReviewer(a.pr.ID, a.reviewerID)
+		st.reviewers[a.pr.ID] = append(st.reviewers[a.pr.ID], memReviewer{
+			reviewerID:   newReviewer,
+			assignedAt:   time.Now(),
+			fromFallback: fromFallback,
+			state:        models.ReviewPending,
+		})
+
+		stored := st.prs[a.pr.ID]
+		stored.Strategy = policy.Strategy
+		st.prs[a.pr.ID] = stored
+
+		err = st.recordPREvents(models.PREvent{
+			PRID:          a.pr.ID,
+			Type:          models.PREventReassigned,
+			OldReviewerID: a.reviewerID,
+			NewReviewerID: newReviewer,
+			Reason:        reason,
+		})
+		if err != nil {
+			return err
+		}
+		report.Reassigned = append(report.Reassigned, models.Reassignment{
+			PRID:        a.pr.ID,
+			OldReviewer: a.reviewerID,
+			NewReviewer: newReviewer,
+		})
+	}
+
+	return nil
+}
+
+// findCandidates возвращает активных участников команды, которые не в отпуске и не исключены,
+// с их текущей нагрузкой и временем последнего назначения
+func (st *memState) findCandidates(teamName string, exclude []string) []selector.Candidate {
+	now := time.Now()
+
+	var candidates []selector.Candidate
+	for _, user := range st.teamMembers(teamName) {
+		if !user.IsActive || slices.Contains(exclude, user.ID) || st.outOfOffice(user.ID, now) {
+			continue
+		}
+		candidates = append(candidates, selector.Candidate{UserID: user.ID, Weight: *user.Weight})
+	}
+
+	for prID, rows := range st.reviewers {
+		open := st.prs[prID].Status == models.StatusOpen
+		for _, row := range rows {
+			for i := range candidates {
+				c := &candidates[i]
+				if c.UserID != row.reviewerID {
+					continue
+				}
+				if c.LastAssignedAt == nil || row.assignedAt.After(*c.LastAssignedAt) {
+					assignedAt := row.assignedAt
+					c.LastAssignedAt = &assignedAt
+				}
+				if open {
+					c.OpenReviews++
+				}
+			}
+		}
+	}
+
+	return candidates
+}
+
+func (st *memState) outOfOffice(userID string, now time.Time) bool {
+	for _, period := range st.ooo {
+		if period.UserID == userID && !period.StartsAt.After(now) && period.EndsAt.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// prWithReviewers собирает PR вместе с назначенными ревьюверами в порядке назначения
+func (st *memState) prWithReviewers(prID string) (*models.PullRequest, error) {
+	stored, ok := st.prs[prID]
+	if !ok {
+		return nil, ErrPRNotFound
+	}
+
+	pr := stored
+	pr.AssignedReviewers = []string{}
+	pr.FallbackReviewers = nil
+	for _, row := range st.reviewers[prID] {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, row.reviewerID)
+		if row.fromFallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, row.reviewerID)
+		}
+	}
+
+	return &pr, nil
+}
+
+// checkApprovals проверяет, что у PR достаточно APPROVED для merge по политике команды автора
+func (st *memState) checkApprovals(pr *models.PullRequest) error {
+	policy, err := st.teamPolicy(st.users[pr.AuthorID].TeamName)
+	if err != nil {
+		return err
+	}
+	if policy.RequiredApprovals == 0 {
+		return nil
+	}
+
+	approvals := 0
+	for _, row := range st.reviewers[pr.ID] {
+		if row.state == models.ReviewApproved {
+			approvals++
+		}
+	}
+
+	if approvals < policy.RequiredApprovals {
+		return ErrNotEnoughApprovals
+	}
+	return nil
+}
+
+// reviewer возвращает назначение для изменения на месте или nil
+func (st *memState) reviewer(prID, reviewerID string) *memReviewer {
+	rows := st.reviewers[prID]
+	for i := range rows {
+		if rows[i].reviewerID == reviewerID {
+			return &rows[i]
+		}
+	}
+	return nil
+}
+
+func (st *memState) removeReviewer(prID, reviewerID string) {
+	rows := slices.DeleteFunc(st.reviewers[prID], func(r memReviewer) bool {
+		return r.reviewerID == reviewerID
+	})
+	if len(rows) == 0 {
+		delete(st.reviewers, prID)
+		return
+	}
+	st.reviewers[prID] = rows
+}
+
+// recordPREvents дописывает события в историю PR и кладёт их в outbox подходящих подписок
+func (st *memState) recordPREvents(events ...models.PREvent) error {
+	now := time.Now()
+	for _, e := range events {
+		st.seq.prEvent++
+		e.ID = st.seq.prEvent
+		e.CreatedAt = now
+		st.prEvents = append(st.prEvents, e)
+	}
+
+	return st.enqueueWebhooks(events, now)
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"review-assignment/internal/lib/businesstime"
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/models"
+)
+
+// SLA METHODS
+
+func (m *Memory) ListOverdueReviews(ctx context.Context, now time.Time) ([]models.OverdueReview, error) {
+	const op = "storage.Memory.ListOverdueReviews"
+
+	var pending []pendingReview
+	err := m.view(ctx, func(st *memState) error {
+		pending = st.overdueReviews(now, m.schedule)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	overdue := make([]models.OverdueReview, len(pending))
+	for i, p := range pending {
+		overdue[i] = p.OverdueReview
+	}
+
+	return overdue, nil
+}
+
+func (m *Memory) ListSLAEvents(ctx context.Context, limit int) ([]models.SLAEvent, error) {
+	const op = "storage.Memory.ListSLAEvents"
+
+	if limit <= 0 {
+		limit = defaultSLAEventsLimit
+	}
+
+	events := []models.SLAEvent{}
+	err := m.view(ctx, func(st *memState) error {
+		events = append(events, st.slaEvents...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.After(events[j].CreatedAt)
+		}
+		return events[i].ID > events[j].ID
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+// ProcessOverdueReviews повторяет Storage.ProcessOverdueReviews: каждое назначение
+// обрабатывается отдельной операцией, ошибки собираются в OverdueReviewError
+func (m *Memory) ProcessOverdueReviews(ctx context.Context, now time.Time, actor string) ([]models.SLAEvent, error) {
+	const op = "storage.Memory.ProcessOverdueReviews"
+
+	var pending []pendingReview
+	err := m.view(ctx, func(st *memState) error {
+		pending = st.overdueReviews(now, m.schedule)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var events []models.SLAEvent
+	var errs []error
+	for _, review := range pending {
+		if review.Escalated && review.action == models.SLAActionEscalate {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		event, err := m.processOverdueReview(ctx, review, actor)
+		if err != nil {
+			errs = append(errs, &OverdueReviewError{PRID: review.PRID, ReviewerID: review.ReviewerID, Err: err})
+			continue
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return events, fmt.Errorf("%s: %w", op, err)
+	}
+	return events, nil
+}
+
+func (m *Memory) processOverdueReview(ctx context.Context, review pendingReview, actor string) (*models.SLAEvent, error) {
+	var event *models.SLAEvent
+	err := m.update(ctx, func(st *memState) error {
+		// назначение могли изменить после чтения списка просроченных
+		if st.prs[review.PRID].Status != models.StatusOpen {
+			return nil
+		}
+		row := st.reviewer(review.PRID, review.ReviewerID)
+		if row == nil || row.state != models.ReviewPending {
+			return nil
+		}
+
+		e := models.SLAEvent{
+			PRID:       review.PRID,
+			ReviewerID: review.ReviewerID,
+			Type:       models.SLAEventEscalated,
+			AssignedAt: review.AssignedAt,
+			Deadline:   review.Deadline,
+		}
+
+		if review.action == models.SLAActionReassign {
+			pr, err := st.prWithReviewers(review.PRID)
+			if err != nil {
+				return err
+			}
+
+			newReviewer, err := m.replaceReviewer(st, pr, review.ReviewerID, models.ReasonSLA)
+			switch {
+			case err == nil:
+				e.Type = models.SLAEventReassigned
+				e.NewReviewerID = newReviewer
+			case errors.Is(err, ErrNoCandidate):
+				if review.Escalated {
+					return nil
+				}
+				e.Reason = ErrNoCandidate.Code
+			default:
+				return err
+			}
+		}
+
+		now := time.Now()
+		if e.Type == models.SLAEventEscalated {
+			st.reviewer(review.PRID, review.ReviewerID).escalatedAt = &now
+		}
+
+		st.seq.slaEvent++
+		e.ID = st.seq.slaEvent
+		e.CreatedAt = now
+		st.slaEvents = append(st.slaEvents, e)
+		event = &e
+		return st.recordAudit(actor, models.AuditProcessOverdueReview, review.PRID, review.OverdueReview, e)
+	})
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	switch {
+	case event.Type == models.SLAEventReassigned:
+		metrics.Reassignments.WithLabelValues(metrics.SourceSLA).Inc()
+	case event.Reason == ErrNoCandidate.Code:
+		metrics.NoCandidate.WithLabelValues(metrics.SourceSLA).Inc()
+	}
+
+	return event, nil
+}
+
+// overdueReviews возвращает PENDING-назначения на OPEN PR, у которых истёк SLA команды автора
+func (st *memState) overdueReviews(now time.Time, schedule businesstime.Schedule) []pendingReview {
+	var pending []pendingReview
+	for prID, rows := range st.reviewers {
+		pr := st.prs[prID]
+		if pr.Status != models.StatusOpen {
+			continue
+		}
+		team := st.teams[st.users[pr.AuthorID].TeamName]
+		if team.SLAHours <= 0 {
+			continue
+		}
+
+		for _, row := range rows {
+			if row.state != models.ReviewPending {
+				continue
+			}
+
+			deadline := schedule.AddHours(row.assignedAt, team.SLAHours)
+			if deadline.After(now) {
+				continue
+			}
+			pending = append(pending, pendingReview{
+				OverdueReview: models.OverdueReview{
+					PRID:       prID,
+					ReviewerID: row.reviewerID,
+					TeamName:   team.TeamName,
+					AssignedAt: row.assignedAt,
+					Deadline:   deadline,
+					Escalated:  row.escalatedAt != nil,
+				},
+				action: team.SLAAction,
+			})
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].AssignedAt.Before(pending[j].AssignedAt)
+	})
+	return pending
+}
@@ -0,0 +1,514 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"review-assignment/internal/models"
+)
+
+// WEBHOOK METHODS
+
+func (m *Memory) AddWebhook(ctx context.Context, req models.AddWebhookRequest) (*models.WebhookSubscription, error) {
+	const op = "storage.Memory.AddWebhook"
+
+	eventTypes, err := validateWebhook(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sub := &models.WebhookSubscription{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: make([]models.PREventType, len(eventTypes)),
+		TeamName:   req.TeamName,
+	}
+	for i, eventType := range eventTypes {
+		sub.EventTypes[i] = models.PREventType(eventType)
+	}
+
+	err = m.update(ctx, func(st *memState) error {
+		if req.TeamName != "" {
+			if _, ok := st.teams[req.TeamName]; !ok {
+				return ErrTeamNotFound
+			}
+		}
+
+		st.seq.webhook++
+		sub.ID = st.seq.webhook
+		sub.CreatedAt = time.Now()
+		st.webhooks = append(st.webhooks, *sub)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sub, nil
+}
+
+func (m *Memory) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	const op = "storage.Memory.ListWebhooks"
+
+	subs := []models.WebhookSubscription{}
+	err := m.view(ctx, func(st *memState) error {
+		for _, sub := range st.webhooks {
+			sub.EventTypes = append([]models.PREventType{}, sub.EventTypes...)
+			subs = append(subs, sub)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subs, nil
+}
+
+func (m *Memory) RemoveWebhook(ctx context.Context, id int64) error {
+	const op = "storage.Memory.RemoveWebhook"
+
+	err := m.update(ctx, func(st *memState) error {
+		i := slices.IndexFunc(st.webhooks, func(sub models.WebhookSubscription) bool { return sub.ID == id })
+		if i < 0 {
+			return ErrWebhookNotFound
+		}
+		st.webhooks = slices.Delete(st.webhooks, i, i+1)
+
+		for deliveryID, d := range st.outbox {
+			if d.SubscriptionID == id {
+				delete(st.outbox, deliveryID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Memory) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error) {
+	const op = "storage.Memory.ListWebhookDeliveries"
+
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	err := m.view(ctx, func(st *memState) error {
+		for _, d := range st.outbox {
+			if (subscriptionID == 0 || d.SubscriptionID == subscriptionID) && (status == "" || d.Status == status) {
+				deliveries = append(deliveries, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (m *Memory) RedeliverWebhook(ctx context.Context, deliveryID int64) (*models.WebhookDelivery, error) {
+	const op = "storage.Memory.RedeliverWebhook"
+
+	var delivery models.WebhookDelivery
+	err := m.update(ctx, func(st *memState) error {
+		d, ok := st.outbox[deliveryID]
+		if !ok {
+			return ErrDeliveryNotFound
+		}
+
+		d.Status = models.DeliveryPending
+		d.Attempts = 0
+		d.NextAttemptAt = time.Now()
+		d.LastError = ""
+		d.DeliveredAt = nil
+		st.outbox[deliveryID] = d
+		delivery = d
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &delivery, nil
+}
+
+func (m *Memory) ClaimWebhookDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	const op = "storage.Memory.ClaimWebhookDeliveries"
+
+	var deliveries []models.WebhookDelivery
+	err := m.update(ctx, func(st *memState) error {
+		for _, d := range st.outbox {
+			if d.Status == models.DeliveryPending && !d.NextAttemptAt.After(now) {
+				deliveries = append(deliveries, d)
+			}
+		}
+		sort.Slice(deliveries, func(i, j int) bool {
+			a, b := deliveries[i], deliveries[j]
+			if !a.NextAttemptAt.Equal(b.NextAttemptAt) {
+				return a.NextAttemptAt.Before(b.NextAttemptAt)
+			}
+			return a.ID < b.ID
+		})
+		if len(deliveries) > limit {
+			deliveries = deliveries[:limit]
+		}
+
+		for i, d := range deliveries {
+			stored := d
+			stored.NextAttemptAt = now.Add(lease)
+			st.outbox[d.ID] = stored
+
+			sub := st.webhooks[slices.IndexFunc(st.webhooks, func(s models.WebhookSubscription) bool { return s.ID == d.SubscriptionID })]
+			deliveries[i].URL = sub.URL
+			deliveries[i].Secret = sub.Secret
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	return deliveries, nil
+}
+
+func (m *Memory) MarkWebhookDelivered(ctx context.Context, deliveryID int64) error {
+	const op = "storage.Memory.MarkWebhookDelivered"
+
+	err := m.update(ctx, func(st *memState) error {
+		d, ok := st.outbox[deliveryID]
+		if !ok {
+			return nil
+		}
+
+		now := time.Now()
+		d.Status = models.DeliveryDelivered
+		d.Attempts++
+		d.LastError = ""
+		d.DeliveredAt = &now
+		st.outbox[deliveryID] = d
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Memory) MarkWebhookFailed(ctx context.Context, deliveryID int64, lastError string, retryAt *time.Time) error {
+	const op = "storage.Memory.MarkWebhookFailed"
+
+	status := models.DeliveryPending
+	nextAttemptAt := time.Now()
+	if retryAt == nil {
+		status = models.DeliveryDead
+	} else {
+		nextAttemptAt = *retryAt
+	}
+
+	err := m.update(ctx, func(st *memState) error {
+		d, ok := st.outbox[deliveryID]
+		if !ok {
+			return nil
+		}
+
+		d.Status = status
+		d.Attempts++
+		d.LastError = lastError
+		d.NextAttemptAt = nextAttemptAt
+		st.outbox[deliveryID] = d
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// enqueueWebhooks кладёт события в outbox для всех подходящих подписок
+func (st *memState) enqueueWebhooks(events []models.PREvent, now time.Time) error {
+	for _, e := range events {
+		team := st.users[st.prs[e.PRID].AuthorID].TeamName
+		var payload []byte
+
+		for _, sub := range st.webhooks {
+			if !webhookMatches(sub, e.Type, team) {
+				continue
+			}
+
+			if payload == nil {
+				var err error
+				payload, err = json.Marshal(models.WebhookEvent{
+					Type:          e.Type,
+					TeamName:      team,
+					PRID:          e.PRID,
+					ReviewerID:    e.ReviewerID,
+					OldReviewerID: e.OldReviewerID,
+					NewReviewerID: e.NewReviewerID,
+					State:         e.State,
+					Reason:        e.Reason,
+					OccurredAt:    now,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			st.seq.delivery++
+			st.outbox[st.seq.delivery] = models.WebhookDelivery{
+				ID:             st.seq.delivery,
+				SubscriptionID: sub.ID,
+				EventType:      e.Type,
+				Payload:        payload,
+				Status:         models.DeliveryPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+			}
+		}
+	}
+
+	return nil
+}
+
+// INTEGRATION METHODS
+
+func (m *Memory) SetExternalAccount(ctx context.Context, req models.SetExternalAccountRequest) (*models.ExternalAccount, error) {
+	const op = "storage.Memory.SetExternalAccount"
+
+	if !integrationProviders[req.Provider] {
+		return nil, fmt.Errorf("%s: %w", op, ErrUnknownProvider)
+	}
+
+	account := models.ExternalAccount{
+		Provider:   req.Provider,
+		Login:      strings.ToLower(req.Login),
+		ExternalID: req.ExternalID,
+		UserID:     req.UserID,
+	}
+	err := m.update(ctx, func(st *memState) error {
+		if _, ok := st.users[req.UserID]; !ok {
+			return ErrUserNotFound
+		}
+
+		key := memAccountKey{provider: account.Provider, login: account.Login}
+		account.CreatedAt = time.Now()
+		if existing, ok := st.accounts[key]; ok {
+			account.CreatedAt = existing.CreatedAt
+			if account.ExternalID == "" {
+				account.ExternalID = existing.ExternalID
+			}
+		}
+		if account.ExternalID != "" {
+			for otherKey, other := range st.accounts {
+				if otherKey != key && other.Provider == account.Provider && other.ExternalID == account.ExternalID {
+					return ErrExternalIDTaken
+				}
+			}
+		}
+		st.accounts[key] = account
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &account, nil
+}
+
+func (m *Memory) ListExternalAccounts(ctx context.Context, provider models.IntegrationProvider) ([]models.ExternalAccount, error) {
+	const op = "storage.Memory.ListExternalAccounts"
+
+	accounts := []models.ExternalAccount{}
+	err := m.view(ctx, func(st *memState) error {
+		for _, account := range st.accounts {
+			if provider == "" || account.Provider == provider {
+				accounts = append(accounts, account)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Provider != accounts[j].Provider {
+			return accounts[i].Provider < accounts[j].Provider
+		}
+		return accounts[i].Login < accounts[j].Login
+	})
+
+	return accounts, nil
+}
+
+func (m *Memory) RemoveExternalAccount(ctx context.Context, provider models.IntegrationProvider, login string) error {
+	const op = "storage.Memory.RemoveExternalAccount"
+
+	err := m.update(ctx, func(st *memState) error {
+		key := memAccountKey{provider: provider, login: strings.ToLower(login)}
+		if _, ok := st.accounts[key]; !ok {
+			return ErrAccountNotFound
+		}
+		delete(st.accounts, key)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Memory) ResolveExternalAccount(ctx context.Context, provider models.IntegrationProvider, login string) (string, error) {
+	const op = "storage.Memory.ResolveExternalAccount"
+
+	var userID string
+	err := m.view(ctx, func(st *memState) error {
+		account, ok := st.accounts[memAccountKey{provider: provider, login: strings.ToLower(login)}]
+		if !ok {
+			return ErrUnknownAccount
+		}
+		userID = account.UserID
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+func (m *Memory) ResolveExternalAccountByID(ctx context.Context, provider models.IntegrationProvider, externalID string) (string, error) {
+	const op = "storage.Memory.ResolveExternalAccountByID"
+
+	var userID string
+	err := m.view(ctx, func(st *memState) error {
+		for _, account := range st.accounts {
+			if account.Provider == provider && account.ExternalID != "" && account.ExternalID == externalID {
+				userID = account.UserID
+				return nil
+			}
+		}
+		return ErrUnknownAccount
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+func (m *Memory) ExternalReviewers(ctx context.Context, provider models.IntegrationProvider, userIDs []string) ([]models.ExternalReviewer, error) {
+	const op = "storage.Memory.ExternalReviewers"
+
+	reviewers := make([]models.ExternalReviewer, len(userIDs))
+	if len(userIDs) == 0 {
+		return reviewers, nil
+	}
+
+	// при нескольких связях берётся первый логин по алфавиту, как в Storage
+	logins := make(map[string]string, len(userIDs))
+	err := m.view(ctx, func(st *memState) error {
+		for _, account := range st.accounts {
+			if account.Provider != provider || !slices.Contains(userIDs, account.UserID) {
+				continue
+			}
+			if login, ok := logins[account.UserID]; !ok || account.Login < login {
+				logins[account.UserID] = account.Login
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, userID := range userIDs {
+		reviewers[i] = models.ExternalReviewer{UserID: userID, Login: logins[userID]}
+	}
+
+	return reviewers, nil
+}
+
+func (m *Memory) ClaimDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID, event string, lease time.Duration) (bool, error) {
+	const op = "storage.Memory.ClaimDelivery"
+
+	claimed := false
+	err := m.update(ctx, func(st *memState) error {
+		key := memDeliveryKey{provider: provider, deliveryID: deliveryID}
+		now := time.Now()
+		delivery, ok := st.deliveries[key]
+		switch {
+		case !ok, !delivery.done && delivery.leaseUntil.Before(now):
+			st.deliveries[key] = memDelivery{leaseUntil: now.Add(lease)}
+			claimed = true
+		case !delivery.done:
+			return ErrDeliveryInProgress
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return claimed, nil
+}
+
+func (m *Memory) CompleteDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID string) error {
+	const op = "storage.Memory.CompleteDelivery"
+
+	err := m.update(ctx, func(st *memState) error {
+		key := memDeliveryKey{provider: provider, deliveryID: deliveryID}
+		if _, ok := st.deliveries[key]; ok {
+			st.deliveries[key] = memDelivery{done: true}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Memory) ReleaseDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID string) error {
+	const op = "storage.Memory.ReleaseDelivery"
+
+	err := m.update(ctx, func(st *memState) error {
+		key := memDeliveryKey{provider: provider, deliveryID: deliveryID}
+		if !st.deliveries[key].done {
+			delete(st.deliveries, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// TestMergeExternalPR проверяет, что merge из GitHub/GitLab фиксируется вопреки политике одобрений
+func TestMergeExternalPR(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+
+			members := createTeam(t, repo, "core", 3)
+			approvals := 1
+			if _, err := repo.SetTeamPolicy(ctx, models.SetTeamPolicyRequest{TeamName: "core", RequiredApprovals: &approvals}, "test"); err != nil {
+				t.Fatalf("set policy: %v", err)
+			}
+			for _, req := range []models.CreatePRRequest{
+				{ID: "open", Name: "open", AuthorID: members[0]},
+				{ID: "draft", Name: "draft", AuthorID: members[0], Draft: true},
+			} {
+				if _, err := repo.CreatePR(ctx, req, "test"); err != nil {
+					t.Fatalf("create %s: %v", req.ID, err)
+				}
+			}
+
+			if _, err := repo.MergePR(ctx, "open", "test"); !errors.Is(err, storage.ErrNotEnoughApprovals) {
+				t.Fatalf("MergePR without approvals: got %v, want %v", err, storage.ErrNotEnoughApprovals)
+			}
+
+			for _, prID := range []string{"open", "draft"} {
+				pr, err := repo.MergeExternalPR(ctx, prID, "github:octocat")
+				if err != nil {
+					t.Fatalf("MergeExternalPR %s: %v", prID, err)
+				}
+				if pr.Status != models.StatusMerged || pr.MergedAt == nil {
+					t.Errorf("PR %s: status %s, merged_at %v; want MERGED with merged_at", prID, pr.Status, pr.MergedAt)
+				}
+			}
+
+			// повторная доставка того же события ничего не меняет
+			if _, err := repo.MergeExternalPR(ctx, "open", "github:octocat"); err != nil {
+				t.Errorf("repeated MergeExternalPR: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"review-assignment/internal/models"
+)
+
+// OUT OF OFFICE METHODS
+
+func (s *Storage) ListOutOfOffice(ctx context.Context, userID string) ([]models.OutOfOffice, error) {
+	const op = "storage.ListOutOfOffice"
+
+	if err := s.ensureUserExists(ctx, s.db, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, starts_at, ends_at, reason
+		FROM user_ooo
+		WHERE user_id = $1
+		ORDER BY starts_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	periods := []models.OutOfOffice{}
+	for rows.Next() {
+		var period models.OutOfOffice
+		if err := rows.Scan(&period.ID, &period.UserID, &period.StartsAt, &period.EndsAt, &period.Reason); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, rows.Err()
+}
+
+func (s *Storage) AddOutOfOffice(ctx context.Context, req models.AddOutOfOfficeRequest) (*models.OutOfOffice, error) {
+	const op = "storage.AddOutOfOffice"
+
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+
+	if err := s.ensureUserExists(ctx, s.db, req.UserID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	period := models.OutOfOffice{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO user_ooo (user_id, starts_at, ends_at, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, req.UserID, req.StartsAt, req.EndsAt, req.Reason).Scan(&period.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &period, nil
+}
+
+func (s *Storage) RemoveOutOfOffice(ctx context.Context, userID string, id int64) error {
+	const op = "storage.RemoveOutOfOffice"
+
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM user_ooo WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, ErrPeriodNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) ensureUserExists(ctx context.Context, q querier, userID string) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
+	`, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/selector"
+)
+
+const maxReviewersLimit = 10
+
+// POLICY METHODS
+
+func (s *Storage) GetTeamPolicy(ctx context.Context, teamName string) (*models.TeamPolicy, error) {
+	const op = "storage.GetTeamPolicy"
+
+	policy, err := s.getTeamPolicy(ctx, s.db, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return policy, nil
+}
+
+func (s *Storage) SetTeamPolicy(ctx context.Context, req models.SetTeamPolicyRequest, actor string) (*models.TeamPolicy, error) {
+	const op = "storage.SetTeamPolicy"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	policy, err := s.getTeamPolicy(ctx, tx, req.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	before := *policy
+
+	if err := applyPolicyRequest(policy, req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.validateFallbackTeams(ctx, tx, policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE teams
+		SET min_reviewers = $1, max_reviewers = $2, assignment_strategy = $3,
+			allow_cross_team = $4, required_approvals = $5, sla_hours = $6, sla_action = $7,
+			updated_at = NOW()
+		WHERE name = $8
+	`, policy.MinReviewers, policy.MaxReviewers, policy.Strategy, policy.AllowCrossTeam,
+		policy.RequiredApprovals, policy.SLAHours, policy.SLAAction, policy.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if req.FallbackTeams != nil {
+		_, err = tx.ExecContext(ctx, `DELETE FROM team_fallbacks WHERE team_name = $1`, policy.TeamName)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for i, fallbackTeam := range policy.FallbackTeams {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO team_fallbacks (team_name, fallback_team, position) VALUES ($1, $2, $3)
+			`, policy.TeamName, fallbackTeam, i)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	if err := s.recordAudit(ctx, tx, actor, models.AuditSetTeamPolicy, policy.TeamName, before, policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return policy, nil
+}
+
+// applyPolicyRequest переносит в policy заданные в запросе поля и проверяет результат.
+// Существование резервных команд проверяет вызывающий.
+func applyPolicyRequest(policy *models.TeamPolicy, req models.SetTeamPolicyRequest) error {
+	if req.MinReviewers != nil {
+		policy.MinReviewers = *req.MinReviewers
+	}
+	if req.MaxReviewers != nil {
+		policy.MaxReviewers = *req.MaxReviewers
+	}
+	if req.Strategy != nil {
+		policy.Strategy = *req.Strategy
+	}
+	if req.AllowCrossTeam != nil {
+		policy.AllowCrossTeam = *req.AllowCrossTeam
+	}
+	if req.FallbackTeams != nil {
+		policy.FallbackTeams = *req.FallbackTeams
+	}
+	if req.RequiredApprovals != nil {
+		policy.RequiredApprovals = *req.RequiredApprovals
+	}
+	if req.SLAHours != nil {
+		policy.SLAHours = *req.SLAHours
+	}
+	if req.SLAAction != nil {
+		policy.SLAAction = *req.SLAAction
+	}
+
+	if policy.MinReviewers < 0 || policy.MaxReviewers < policy.MinReviewers || policy.MaxReviewers > maxReviewersLimit {
+		return ErrInvalidPolicy
+	}
+	if policy.RequiredApprovals < 0 || policy.RequiredApprovals > maxReviewersLimit {
+		return ErrInvalidPolicy
+	}
+	if policy.SLAHours < 0 {
+		return ErrInvalidPolicy
+	}
+	if policy.SLAAction != models.SLAActionEscalate && policy.SLAAction != models.SLAActionReassign {
+		return ErrInvalidPolicy
+	}
+	if !selector.Valid(policy.Strategy) {
+		return ErrInvalidStrategy
+	}
+
+	return nil
+}
+
+func (s *Storage) getTeamPolicy(ctx context.Context, q querier, teamName string) (*models.TeamPolicy, error) {
+	const op = "storage.getTeamPolicy"
+
+	var policy models.TeamPolicy
+	var strategy, slaAction string
+	err := q.QueryRowContext(ctx, `
+		SELECT name, min_reviewers, max_reviewers, assignment_strategy, allow_cross_team,
+			required_approvals, sla_hours, sla_action
+		FROM teams
+		WHERE name = $1
+	`, teamName).Scan(&policy.TeamName, &policy.MinReviewers, &policy.MaxReviewers, &strategy,
+		&policy.AllowCrossTeam, &policy.RequiredApprovals, &policy.SLAHours, &slaAction)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", op, ErrTeamNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	policy.Strategy = models.AssignmentStrategy(strategy)
+	policy.SLAAction = models.SLAAction(slaAction)
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT fallback_team FROM team_fallbacks
+		WHERE team_name = $1
+		ORDER BY position
+	`, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	policy.FallbackTeams = []string{}
+	for rows.Next() {
+		var fallbackTeam string
+		if err := rows.Scan(&fallbackTeam); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		policy.FallbackTeams = append(policy.FallbackTeams, fallbackTeam)
+	}
+
+	return &policy, rows.Err()
+}
+
+func (s *Storage) validateFallbackTeams(ctx context.Context, q querier, policy *models.TeamPolicy) error {
+	seen := make(map[string]bool, len(policy.FallbackTeams))
+
+	for _, team := range policy.FallbackTeams {
+		if team == policy.TeamName || seen[team] {
+			return ErrInvalidPolicy
+		}
+		seen[team] = true
+
+		var exists bool
+		err := q.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)
+		`, team).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrInvalidPolicy
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"review-assignment/internal/models"
+)
+
+// TeamRepository — команды и их политики назначения
+type TeamRepository interface {
+	CreateTeam(ctx context.Context, team models.Team, actor string) error
+	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	GetTeamPolicy(ctx context.Context, teamName string) (*models.TeamPolicy, error)
+	SetTeamPolicy(ctx context.Context, req models.SetTeamPolicyRequest, actor string) (*models.TeamPolicy, error)
+	DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string, actor string) (*models.TeamDeactivationResult, error)
+	RemoveTeamMember(ctx context.Context, teamName, userID, actor string) (*models.TeamMemberChange, error)
+	MoveTeamMember(ctx context.Context, req models.MoveTeamMemberRequest, actor string) (*models.TeamMemberChange, error)
+	DeleteTeam(ctx context.Context, teamName, actor string) error
+}
+
+// UserRepository — пользователи, их ревью и периоды отсутствия
+type UserRepository interface {
+	SetUserActive(ctx context.Context, userID string, isActive bool, actor string) (*models.User, *models.ReassignmentReport, error)
+	GetUserReviews(ctx context.Context, userID string) ([]models.PullRequest, error)
+	ListOutOfOffice(ctx context.Context, userID string) ([]models.OutOfOffice, error)
+	AddOutOfOffice(ctx context.Context, req models.AddOutOfOfficeRequest) (*models.OutOfOffice, error)
+	RemoveOutOfOffice(ctx context.Context, userID string, id int64) error
+}
+
+// PRRepository — PR, назначение ревьюверов, ревью и SLA
+type PRRepository interface {
+	GetPR(ctx context.Context, prID string) (*models.PRDetails, error)
+	CreatePR(ctx context.Context, req models.CreatePRRequest, actor string) (*models.PullRequest, error)
+	MergePR(ctx context.Context, prID string, actor string) (*models.PullRequest, error)
+	MergeExternalPR(ctx context.Context, prID string, actor string) (*models.PullRequest, error)
+	ReassignReviewer(ctx context.Context, req models.ReassignRequest, actor string) (*models.PullRequest, string, error)
+	MarkReady(ctx context.Context, prID string, actor string) (*models.PullRequest, error)
+	ReopenPR(ctx context.Context, prID string, actor string) (*models.PullRequest, error)
+	ClosePR(ctx context.Context, prID string, actor string) (*models.PullRequest, error)
+	ListPRs(ctx context.Context, filter models.PRListFilter) (*models.PRListPage, error)
+	SubmitReview(ctx context.Context, req models.SubmitReviewRequest, actor string) (*models.Review, error)
+	ListOverdueReviews(ctx context.Context, now time.Time) ([]models.OverdueReview, error)
+	ListSLAEvents(ctx context.Context, limit int) ([]models.SLAEvent, error)
+	ProcessOverdueReviews(ctx context.Context, now time.Time, actor string) ([]models.SLAEvent, error)
+	CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+// AuditRepository — журнал аудита изменений
+type AuditRepository interface {
+	ListAudit(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error)
+	ExportAudit(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error
+}
+
+// WebhookRepository — подписки на события и outbox исходящих доставок
+type WebhookRepository interface {
+	AddWebhook(ctx context.Context, req models.AddWebhookRequest) (*models.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error)
+	RemoveWebhook(ctx context.Context, id int64) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, deliveryID int64) (*models.WebhookDelivery, error)
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, deliveryID int64) error
+	MarkWebhookFailed(ctx context.Context, deliveryID int64, lastError string, retryAt *time.Time) error
+}
+
+// IntegrationRepository — связи с аккаунтами GitHub/GitLab и дедупликация входящих доставок
+type IntegrationRepository interface {
+	SetExternalAccount(ctx context.Context, req models.SetExternalAccountRequest) (*models.ExternalAccount, error)
+	ListExternalAccounts(ctx context.Context, provider models.IntegrationProvider) ([]models.ExternalAccount, error)
+	RemoveExternalAccount(ctx context.Context, provider models.IntegrationProvider, login string) error
+	ResolveExternalAccount(ctx context.Context, provider models.IntegrationProvider, login string) (string, error)
+	ResolveExternalAccountByID(ctx context.Context, provider models.IntegrationProvider, externalID string) (string, error)
+	ExternalReviewers(ctx context.Context, provider models.IntegrationProvider, userIDs []string) ([]models.ExternalReviewer, error)
+	ClaimDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID, event string, lease time.Duration) (bool, error)
+	CompleteDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID string) error
+	ReleaseDelivery(ctx context.Context, provider models.IntegrationProvider, deliveryID string) error
+}
+
+// Repository — всё хранилище сервиса. Реализации обязаны возвращать одни и те же
+// ошибки (ErrTeamNotFound, ErrPRMerged, ...) в одних и тех же ситуациях.
+type Repository interface {
+	TeamRepository
+	UserRepository
+	PRRepository
+	AuditRepository
+	WebhookRepository
+	IntegrationRepository
+}
+
+var (
+	_ Repository = (*Storage)(nil)
+	_ Repository = (*Memory)(nil)
+)
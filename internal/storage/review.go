@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"review-assignment/internal/models"
+)
+
+// REVIEW METHODS
+
+func (s *Storage) SubmitReview(ctx context.Context, req models.SubmitReviewRequest, actor string) (*models.Review, error) {
+	const op = "storage.SubmitReview"
+
+	switch req.State {
+	case models.ReviewApproved, models.ReviewChangesRequested, models.ReviewDismissed:
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidReviewState)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `
+		SELECT status FROM pull_requests WHERE pull_request_id = $1 FOR SHARE
+	`, req.PRID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", op, ErrPRNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if models.PRStatus(status) == models.StatusMerged {
+		return nil, fmt.Errorf("%s: %w", op, ErrPRMerged)
+	}
+	if models.PRStatus(status) != models.StatusOpen {
+		return nil, fmt.Errorf("%s: %w", op, ErrPRNotOpen)
+	}
+
+	before := models.Review{PRID: req.PRID, ReviewerID: req.ReviewerID}
+	var beforeState string
+	var beforeUpdatedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT state, state_updated_at FROM pr_reviewers
+		WHERE pr_id = $1 AND reviewer_id = $2
+		FOR UPDATE
+	`, req.PRID, req.ReviewerID).Scan(&beforeState, &beforeUpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", op, ErrNotAssigned)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	before.State = models.ReviewState(beforeState)
+	if beforeUpdatedAt.Valid {
+		before.UpdatedAt = &beforeUpdatedAt.Time
+	}
+
+	review := models.Review{
+		PRID:       req.PRID,
+		ReviewerID: req.ReviewerID,
+		State:      req.State,
+	}
+	var updatedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		UPDATE pr_reviewers
+		SET state = $1, state_updated_at = NOW()
+		WHERE pr_id = $2 AND reviewer_id = $3
+		RETURNING state_updated_at
+	`, req.State, req.PRID, req.ReviewerID).Scan(&updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if updatedAt.Valid {
+		review.UpdatedAt = &updatedAt.Time
+	}
+
+	err = s.recordPREvents(ctx, tx, models.PREvent{
+		PRID:       req.PRID,
+		Type:       models.PREventReviewed,
+		ReviewerID: req.ReviewerID,
+		State:      req.State,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.recordAudit(ctx, tx, actor, models.AuditSubmitReview, req.PRID, before, review); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &review, nil
+}
+
+// checkApprovals проверяет, что у PR достаточно APPROVED для merge по политике команды автора
+func (s *Storage) checkApprovals(ctx context.Context, q querier, pr *models.PullRequest) error {
+	var authorTeam string
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1
+	`, pr.AuthorID).Scan(&authorTeam)
+	if err != nil {
+		return err
+	}
+
+	policy, err := s.getTeamPolicy(ctx, q, authorTeam)
+	if err != nil {
+		return err
+	}
+	if policy.RequiredApprovals == 0 {
+		return nil
+	}
+
+	var approvals int
+	err = q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM pr_reviewers
+		WHERE pr_id = $1 AND state = $2
+	`, pr.ID, models.ReviewApproved).Scan(&approvals)
+	if err != nil {
+		return err
+	}
+
+	if approvals < policy.RequiredApprovals {
+		return ErrNotEnoughApprovals
+	}
+	return nil
+}
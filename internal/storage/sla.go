@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"review-assignment/internal/metrics"
+	"review-assignment/internal/models"
+)
+
+const defaultSLAEventsLimit = 100
+
+type pendingReview struct {
+	models.OverdueReview
+	action models.SLAAction
+}
+
+// OverdueReviewError — ошибка обработки одного просроченного ревью
+type OverdueReviewError struct {
+	PRID       string
+	ReviewerID string
+	Err        error
+}
+
+func (e *OverdueReviewError) Error() string {
+	return fmt.Sprintf("PR %s, reviewer %s: %v", e.PRID, e.ReviewerID, e.Err)
+}
+
+func (e *OverdueReviewError) Unwrap() error {
+	return e.Err
+}
+
+// SLA METHODS
+
+func (s *Storage) ListOverdueReviews(ctx context.Context, now time.Time) ([]models.OverdueReview, error) {
+	const op = "storage.ListOverdueReviews"
+
+	pending, err := s.loadOverdueReviews(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	overdue := make([]models.OverdueReview, len(pending))
+	for i, p := range pending {
+		overdue[i] = p.OverdueReview
+	}
+
+	return overdue, nil
+}
+
+func (s *Storage) ListSLAEvents(ctx context.Context, limit int) ([]models.SLAEvent, error) {
+	const op = "storage.ListSLAEvents"
+
+	if limit <= 0 {
+		limit = defaultSLAEventsLimit
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, pr_id, reviewer_id, event_type, COALESCE(new_reviewer_id, ''), reason,
+			assigned_at, deadline, created_at
+		FROM sla_events
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	events := []models.SLAEvent{}
+	for rows.Next() {
+		var e models.SLAEvent
+		var eventType string
+		if err := rows.Scan(&e.ID, &e.PRID, &e.ReviewerID, &eventType, &e.NewReviewerID, &e.Reason,
+			&e.AssignedAt, &e.Deadline, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		e.Type = models.SLAEventType(eventType)
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// ProcessOverdueReviews эскалирует или переназначает просроченные ревью согласно политике команды автора.
+// Каждое назначение обрабатывается в отдельной транзакции; строки, уже захваченные другой репликой, пропускаются.
+// Ошибка на одном назначении не останавливает остальные: вместе с событиями возвращаются
+// все ошибки, объединённые errors.Join, по одной OverdueReviewError на назначение.
+func (s *Storage) ProcessOverdueReviews(ctx context.Context, now time.Time, actor string) ([]models.SLAEvent, error) {
+	const op = "storage.ProcessOverdueReviews"
+
+	pending, err := s.loadOverdueReviews(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	events, err := s.processOverdueReviews(ctx, pending, actor)
+	if err != nil {
+		return events, fmt.Errorf("%s: %w", op, err)
+	}
+	return events, nil
+}
+
+// processOverdueReviews обрабатывает выборку loadOverdueReviews. К этому моменту PR и назначения
+// могли измениться, поэтому каждое из них заново проверяется под блокировкой.
+func (s *Storage) processOverdueReviews(ctx context.Context, pending []pendingReview, actor string) ([]models.SLAEvent, error) {
+	var events []models.SLAEvent
+	var errs []error
+	for _, review := range pending {
+		if review.Escalated && review.action == models.SLAActionEscalate {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		event, err := s.processOverdueReview(ctx, review, actor)
+		if err != nil {
+			errs = append(errs, &OverdueReviewError{PRID: review.PRID, ReviewerID: review.ReviewerID, Err: err})
+			continue
+		}
+		if event != nil {
+			events = append(events, *event)
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+func (s *Storage) processOverdueReview(ctx context.Context, review pendingReview, actor string) (*models.SLAEvent, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// PR блокируется раньше назначения, в том же порядке, что и при ручном переназначении.
+	// PR, который сейчас меняет другой запрос, обработается на следующем проходе,
+	// а успевший с момента выборки закрыться или влиться пропускается.
+	var status models.PRStatus
+	err = tx.QueryRowContext(ctx, `
+		SELECT status FROM pull_requests WHERE pull_request_id = $1
+		FOR UPDATE SKIP LOCKED
+	`, review.PRID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != models.StatusOpen {
+		return nil, nil
+	}
+
+	var locked int
+	err = tx.QueryRowContext(ctx, `
+		SELECT 1 FROM pr_reviewers
+		WHERE pr_id = $1 AND reviewer_id = $2 AND state = $3
+		FOR UPDATE SKIP LOCKED
+	`, review.PRID, review.ReviewerID, models.ReviewPending).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event := models.SLAEvent{
+		PRID:       review.PRID,
+		ReviewerID: review.ReviewerID,
+		Type:       models.SLAEventEscalated,
+		AssignedAt: review.AssignedAt,
+		Deadline:   review.Deadline,
+	}
+
+	if review.action == models.SLAActionReassign {
+		pr, err := s.getPRWithReviewers(ctx, tx, review.PRID)
+		if err != nil {
+			return nil, err
+		}
+
+		newReviewer, err := s.replaceReviewer(ctx, tx, pr, review.ReviewerID, models.ReasonSLA)
+		switch {
+		case err == nil:
+			event.Type = models.SLAEventReassigned
+			event.NewReviewerID = newReviewer
+		case errors.Is(err, ErrNoCandidate):
+			if review.Escalated {
+				return nil, nil
+			}
+			event.Reason = ErrNoCandidate.Code
+		default:
+			return nil, err
+		}
+	}
+
+	if event.Type == models.SLAEventEscalated {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE pr_reviewers SET escalated_at = NOW()
+			WHERE pr_id = $1 AND reviewer_id = $2
+		`, review.PRID, review.ReviewerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO sla_events (pr_id, reviewer_id, event_type, new_reviewer_id, reason, assigned_at, deadline)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+		RETURNING id, created_at
+	`, event.PRID, event.ReviewerID, event.Type, event.NewReviewerID, event.Reason,
+		event.AssignedAt, event.Deadline).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAudit(ctx, tx, actor, models.AuditProcessOverdueReview, review.PRID, review.OverdueReview, event); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case event.Type == models.SLAEventReassigned:
+		metrics.Reassignments.WithLabelValues(metrics.SourceSLA).Inc()
+	case event.Reason == ErrNoCandidate.Code:
+		metrics.NoCandidate.WithLabelValues(metrics.SourceSLA).Inc()
+	}
+
+	return &event, nil
+}
+
+// loadOverdueReviews возвращает PENDING-назначения на OPEN PR, у которых истёк SLA команды автора.
+// SQL отсекает назначения по календарным часам, точный дедлайн в рабочих часах считается в Go.
+func (s *Storage) loadOverdueReviews(ctx context.Context, now time.Time) ([]pendingReview, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT prr.pr_id, prr.reviewer_id, t.name, prr.assigned_at, prr.escalated_at IS NOT NULL,
+			t.sla_hours, t.sla_action
+		FROM pr_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pr_id
+		JOIN users a ON a.user_id = pr.author_id
+		JOIN teams t ON t.name = a.team_name
+		WHERE pr.status = $1 AND prr.state = $2 AND t.sla_hours > 0
+		AND prr.assigned_at <= $3::timestamptz - make_interval(hours => t.sla_hours)
+		ORDER BY prr.assigned_at
+	`, models.StatusOpen, models.ReviewPending, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []pendingReview
+	for rows.Next() {
+		var p pendingReview
+		var slaHours int
+		var action string
+		if err := rows.Scan(&p.PRID, &p.ReviewerID, &p.TeamName, &p.AssignedAt, &p.Escalated,
+			&slaHours, &action); err != nil {
+			return nil, err
+		}
+
+		p.Deadline = s.schedule.AddHours(p.AssignedAt, slaHours)
+		if p.Deadline.After(now) {
+			continue
+		}
+		p.action = models.SLAAction(action)
+		pending = append(pending, p)
+	}
+
+	return pending, rows.Err()
+}
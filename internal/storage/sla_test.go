@@ -0,0 +1,122 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"review-assignment/internal/database"
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// TestProcessOverdueReviewsContinuesAfterFailure проверяет, что ошибка на одном PR
+// не мешает обработать остальные просроченные ревью
+func TestProcessOverdueReviewsContinuesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	repo, closeDB := openSQLiteAt(t, path)
+	t.Cleanup(closeDB)
+
+	members := createTeam(t, repo, "core", 4)
+	slaHours := 1
+	if _, err := repo.SetTeamPolicy(ctx, models.SetTeamPolicyRequest{TeamName: "core", SLAHours: &slaHours}, "test"); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		if _, err := repo.CreatePR(ctx, models.CreatePRRequest{ID: id, Name: "x", AuthorID: members[0]}, "test"); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+
+	// запись SLA-события по pr-2 падает, как упала бы на сбое базы
+	db, err := database.NewSQLite(path)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TRIGGER fail_pr_2 BEFORE INSERT ON sla_events
+		WHEN NEW.pr_id = 'pr-2'
+		BEGIN SELECT RAISE(ABORT, 'injected failure'); END
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+
+	events, err := repo.ProcessOverdueReviews(ctx, time.Now().AddDate(0, 1, 0), "system:sla")
+
+	var reviewErr *storage.OverdueReviewError
+	if !errors.As(err, &reviewErr) || reviewErr.PRID != "pr-2" {
+		t.Fatalf("got error %v, want OverdueReviewError for pr-2", err)
+	}
+	processed := make(map[string]bool)
+	for _, event := range events {
+		processed[event.PRID] = true
+	}
+	if !processed["pr-1"] || !processed["pr-3"] || processed["pr-2"] {
+		t.Errorf("processed PRs %v, want pr-1 and pr-3", processed)
+	}
+}
+
+// TestProcessOverdueReviewsSkipsMergedPR проверяет, что PR, влитый между выборкой просроченных
+// ревью и их обработкой, не переназначается и не получает SLA-событий
+func TestProcessOverdueReviewsSkipsMergedPR(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, ok := backend.open(t).(*storage.Storage)
+			if !ok {
+				t.Skip("memory backend checks the PR status under the same lock as the change")
+			}
+
+			members := createTeam(t, repo, "core", 5)
+			slaHours, action := 1, models.SLAActionReassign
+			_, err := repo.SetTeamPolicy(ctx, models.SetTeamPolicyRequest{TeamName: "core", SLAHours: &slaHours, SLAAction: &action}, "test")
+			if err != nil {
+				t.Fatalf("set policy: %v", err)
+			}
+			merged, err := repo.CreatePR(ctx, models.CreatePRRequest{ID: "merged", Name: "x", AuthorID: members[0]}, "test")
+			if err != nil {
+				t.Fatalf("create merged: %v", err)
+			}
+			if _, err := repo.CreatePR(ctx, models.CreatePRRequest{ID: "open", Name: "x", AuthorID: members[0]}, "test"); err != nil {
+				t.Fatalf("create open: %v", err)
+			}
+
+			batch, err := repo.LoadOverdueReviews(ctx, time.Now().AddDate(0, 1, 0))
+			if err != nil {
+				t.Fatalf("load overdue: %v", err)
+			}
+			if _, err := repo.MergeExternalPR(ctx, "merged", "github:octocat"); err != nil {
+				t.Fatalf("merge: %v", err)
+			}
+
+			events, err := repo.ProcessOverdueBatch(ctx, batch, "system:sla")
+			if err != nil {
+				t.Fatalf("process overdue: %v", err)
+			}
+			if len(events) == 0 {
+				t.Fatal("open PR was not processed")
+			}
+			for _, event := range events {
+				if event.PRID != "open" {
+					t.Errorf("got SLA event %s for %s, want only the open PR", event.Type, event.PRID)
+				}
+			}
+
+			details, err := repo.GetPR(ctx, "merged")
+			if err != nil {
+				t.Fatalf("get merged: %v", err)
+			}
+			// ревьюверы назначены в один момент, поэтому их порядок не определён
+			got, want := slices.Sorted(slices.Values(details.PR.AssignedReviewers)), slices.Sorted(slices.Values(merged.AssignedReviewers))
+			if !slices.Equal(got, want) {
+				t.Errorf("merged PR reviewers changed from %v to %v", merged.AssignedReviewers, details.PR.AssignedReviewers)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"review-assignment/internal/models"
+)
+
+// STATS METHODS
+
+// CountOpenReviewsByTeam возвращает число назначений на OPEN PR по командам ревьюверов.
+// Команды без открытых ревью тоже попадают в результат с нулём.
+func (s *Storage) CountOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	const op = "storage.CountOpenReviewsByTeam"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.name, COUNT(pr.pull_request_id)
+		FROM teams t
+		LEFT JOIN users u ON u.team_name = t.name
+		LEFT JOIN pr_reviewers prr ON prr.reviewer_id = u.user_id
+		LEFT JOIN pull_requests pr ON pr.pull_request_id = prr.pr_id AND pr.status = $1
+		GROUP BY t.name
+	`, models.StatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var team string
+		var n int
+		if err := rows.Scan(&team, &n); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		counts[team] = n
+	}
+
+	return counts, rows.Err()
+}
@@ -1,97 +1,183 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"math/rand"
 	"time"
 
+	"review-assignment/internal/apperr"
+	"review-assignment/internal/lib/businesstime"
+	"review-assignment/internal/metrics"
 	"review-assignment/internal/models"
+	"review-assignment/internal/selector"
 
 	_ "github.com/lib/pq"
 )
 
 var (
-	ErrTeamExists     = errors.New("TEAM_EXISTS")
-	ErrPRExists       = errors.New("PR_EXISTS")
-	ErrPRMerged       = errors.New("PR_MERGED")
-	ErrNotFound       = errors.New("NOT_FOUND")
-	ErrNotAssigned    = errors.New("NOT_ASSIGNED")
-	ErrNoCandidate    = errors.New("NO_CANDIDATE")
-	ErrAuthorNotFound = errors.New("AUTHOR_NOT_FOUND")
+	ErrNotFound           = apperr.New(apperr.CodeNotFound, "resource not found")
+	ErrTeamNotFound       = ErrNotFound.WithMessage("team not found")
+	ErrUserNotFound       = ErrNotFound.WithMessage("user not found")
+	ErrAuthorNotFound     = ErrNotFound.WithMessage("author not found")
+	ErrMemberNotFound     = ErrNotFound.WithMessage("team or team member not found")
+	ErrPRNotFound         = ErrNotFound.WithMessage("PR not found")
+	ErrPeriodNotFound     = ErrNotFound.WithMessage("out-of-office period not found")
+	ErrWebhookNotFound    = ErrNotFound.WithMessage("webhook subscription not found")
+	ErrDeliveryNotFound   = ErrNotFound.WithMessage("webhook delivery not found")
+	ErrAccountNotFound    = ErrNotFound.WithMessage("external account not found")
+	ErrTeamExists         = apperr.New(apperr.CodeTeamExists, "team already exists")
+	ErrPRExists           = apperr.New(apperr.CodePRExists, "PR already exists")
+	ErrPRMerged           = apperr.New(apperr.CodePRMerged, "PR is already merged")
+	ErrPRNotOpen          = apperr.New(apperr.CodePRNotOpen, "PR is draft or closed")
+	ErrNotAssigned        = apperr.New(apperr.CodeNotAssigned, "reviewer is not assigned to this PR")
+	ErrNoCandidate        = apperr.New(apperr.CodeNoCandidate, "no active replacement candidate in team")
+	ErrNotEnoughReviewers = apperr.New(apperr.CodeNotEnoughReviewers, "not enough active reviewers to satisfy team policy")
+	ErrNotEnoughApprovals = apperr.New(apperr.CodeNotEnoughApprovals, "PR does not have enough approvals to be merged")
+	ErrInvalidTransition  = apperr.New(apperr.CodeInvalidTransition, "PR status does not allow this transition")
+	ErrUnknownAccount     = apperr.New(apperr.CodeUnknownAccount, "external login is not linked to a user")
+	ErrTeamNotEmpty       = apperr.New(apperr.CodeTeamNotEmpty, "team still has members: remove or move them first")
+	ErrMemberHasOpenPRs   = apperr.New(apperr.CodeMemberHasOpenPRs, "member still authors open or draft PRs")
+	ErrConcurrentUpdate   = apperr.New(apperr.CodeConflict, "concurrent update, please retry")
+	ErrExternalIDTaken    = apperr.New(apperr.CodeConflict, "external_id is already linked to another login")
+	ErrDeliveryInProgress = apperr.New(apperr.CodeDeliveryInProgress, "delivery is still being processed, retry later")
+	ErrInvalidInput       = apperr.New(apperr.CodeInvalidInput, "invalid input")
+	ErrInvalidStrategy    = ErrInvalidInput.WithMessage("unknown assignment strategy")
+	ErrInvalidWeight      = ErrInvalidInput.WithMessage("review_weight must be >= 0")
+	ErrInvalidPolicy      = ErrInvalidInput.WithMessage("invalid team policy: check reviewer limits (0 <= min <= max <= 10), required_approvals, sla_hours, sla_action and fallback_teams")
+	ErrInvalidPeriod      = ErrInvalidInput.WithMessage("ends_at must be after starts_at")
+	ErrInvalidReviewState = ErrInvalidInput.WithMessage("state must be one of APPROVED, CHANGES_REQUESTED, DISMISSED")
+	ErrInvalidCursor      = ErrInvalidInput.WithMessage("invalid cursor")
+	ErrInvalidOpenReviews = ErrInvalidInput.WithMessage("open_reviews must be one of keep, reassign")
+	ErrSameTeam           = ErrInvalidInput.WithMessage("user is already in this team")
+	ErrInvalidWebhook     = ErrInvalidInput.WithMessage("url must be http(s) and event_types must be known PR event types")
+	ErrUnknownProvider    = ErrInvalidInput.WithMessage("unknown provider")
 )
 
+// querier позволяет выполнять одни и те же запросы как через *sql.DB, так и внутри *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 type Storage struct {
-	db  *sql.DB
-	rng *rand.Rand
+	db  *sqlDB
+	rng selector.Rand
+	// schedule — рабочее время, в котором считается SLA
+	schedule businesstime.Schedule
 }
 
-func New(db *sql.DB) *Storage {
-	source := rand.NewSource(time.Now().UnixNano())
-	rng := rand.New(source)
+// New создаёт хранилище поверх PostgreSQL
+func New(db *sql.DB, schedule businesstime.Schedule) *Storage {
+	return &Storage{
+		db:       &sqlDB{DB: db, dialect: postgresDialect{}},
+		rng:      selector.SafeRand(),
+		schedule: schedule,
+	}
+}
 
+// NewSQLite создаёт хранилище поверх SQLite; db должна быть открыта через database.NewSQLite
+func NewSQLite(db *sql.DB, schedule businesstime.Schedule) *Storage {
 	return &Storage{
-		db:  db,
-		rng: rng,
+		db:       &sqlDB{DB: db, dialect: sqliteDialect{}, writer: make(chan struct{}, 1)},
+		rng:      selector.SafeRand(),
+		schedule: schedule,
 	}
 }
 
 // TEAM METHODS
 
-func (s *Storage) CreateTeam(team models.Team) error {
+func (s *Storage) CreateTeam(ctx context.Context, team models.Team, actor string) error {
 	const op = "storage.CreateTeam"
 
-	tx, err := s.db.Begin()
+	if team.Strategy == "" {
+		team.Strategy = models.StrategyRandom
+	}
+	if !selector.Valid(team.Strategy) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidStrategy)
+	}
+	if !validWeights(team.Members) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidWeight)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec(`
-        INSERT INTO teams (name) VALUES ($1)
-    `, team.Name)
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO teams (name, assignment_strategy) VALUES ($1, $2)
+    `, team.Name, team.Strategy)
 	if err != nil {
-		if err.Error() == "pq: duplicate key value violates unique constraint \"teams_pkey\"" {
+		if apperr.IsUniqueViolation(err, "teams_pkey") {
 			return fmt.Errorf("%s: %w", op, ErrTeamExists)
 		}
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	// участники могли состоять в других командах: их прежнее состояние попадает в аудит
+	var before interface{}
+	movedUsers, err := s.getUsers(ctx, tx, team.Members)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(movedUsers) > 0 {
+		before = movedUsers
+	}
+
+	// вес, не переданный в запросе, у нового участника равен 1, а у существующего не меняется
 	for _, member := range team.Members {
-		_, err := tx.Exec(`
-            INSERT INTO users (user_id, username, team_name, is_active) 
-            VALUES ($1, $2, $3, $4)
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO users (user_id, username, team_name, is_active, review_weight) 
+            VALUES ($1, $2, $3, $4, COALESCE($5, 1))
             ON CONFLICT (user_id) DO UPDATE SET 
                 username = EXCLUDED.username, 
                 team_name = EXCLUDED.team_name, 
                 is_active = EXCLUDED.is_active,
+                review_weight = COALESCE($5, users.review_weight),
                 updated_at = NOW()
-        `, member.ID, member.Username, team.Name, member.IsActive)
+        `, member.ID, member.Username, team.Name, member.IsActive, member.Weight)
 		if err != nil {
 			return fmt.Errorf("%s: %w", op, err)
 		}
 	}
 
+	if err := s.recordAudit(ctx, tx, actor, models.AuditCreateTeam, team.Name, before, team); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	return tx.Commit()
 }
 
-func (s *Storage) GetTeam(teamName string) (*models.Team, error) {
+// validWeights проверяет веса участников: 0 допустим и исключает участника из стратегии weighted
+func validWeights(members []models.User) bool {
+	for _, member := range members {
+		if member.Weight != nil && *member.Weight < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Storage) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
 	const op = "storage.GetTeam"
 
-	var teamExists bool
-	err := s.db.QueryRow(`
-        SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)
-    `, teamName).Scan(&teamExists)
+	var strategy string
+	err := s.db.QueryRowContext(ctx, `
+        SELECT assignment_strategy FROM teams WHERE name = $1
+    `, teamName).Scan(&strategy)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", op, ErrTeamNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	if !teamExists {
-		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
-	}
 
-	rows, err := s.db.Query(`
-        SELECT user_id, username, is_active 
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT user_id, username, is_active, review_weight 
         FROM users 
         WHERE team_name = $1
         ORDER BY user_id
@@ -104,56 +190,95 @@ func (s *Storage) GetTeam(teamName string) (*models.Team, error) {
 	var members []models.User
 	for rows.Next() {
 		var user models.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.IsActive); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.IsActive, &user.Weight); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
 		members = append(members, user)
 	}
 
 	return &models.Team{
-		Name:    teamName,
-		Members: members,
+		Name:     teamName,
+		Strategy: models.AssignmentStrategy(strategy),
+		Members:  members,
 	}, nil
 }
 
 // USER METHODS
 
-func (s *Storage) SetUserActive(userID string, isActive bool) (*models.User, error) {
+func (s *Storage) SetUserActive(ctx context.Context, userID string, isActive bool, actor string) (*models.User, *models.ReassignmentReport, error) {
 	const op = "storage.SetUserActive"
 
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var before models.User
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active
+		FROM users WHERE user_id = $1
+		FOR UPDATE
+	`, userID).Scan(&before.ID, &before.Username, &before.TeamName, &before.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	var user models.User
-	err := s.db.QueryRow(`
+	err = tx.QueryRowContext(ctx, `
 		UPDATE users 
 		SET is_active = $1, updated_at = NOW() 
 		WHERE user_id = $2
-		RETURNING user_id, username, team_name, is_active
+		RETURNING user_id, username, COALESCE(team_name, ''), is_active
 	`, isActive, userID).Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+	report := &models.ReassignmentReport{
+		Reassigned:    []models.Reassignment{},
+		NotReassigned: []models.ReassignmentFailure{},
 	}
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	if !isActive {
+		if err := s.reassignOpenReviews(ctx, tx, []string{userID}, models.ReasonUserDeactivated, report); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	after := struct {
+		*models.User
+		*models.ReassignmentReport
+	}{&user, report}
+	if err := s.recordAudit(ctx, tx, actor, models.AuditSetUserActive, userID, before, after); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &user, nil
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	observeReassignments(metrics.SourceDeactivated, report)
+
+	return &user, report, nil
 }
 
-func (s *Storage) GetUserReviews(userID string) ([]models.PullRequest, error) {
+func (s *Storage) GetUserReviews(ctx context.Context, userID string) ([]models.PullRequest, error) {
 	const op = "storage.GetUserReviews"
 
 	var userExists bool
-	err := s.db.QueryRow(`
+	err := s.db.QueryRowContext(ctx, `
 		SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)
 	`, userID).Scan(&userExists)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	if !userExists {
-		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		return nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
 	}
 
-	rows, err := s.db.Query(`
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT 
 			pr.pull_request_id, pr.pull_request_name, 
 			pr.author_id, pr.status, pr.created_at, pr.merged_at
@@ -193,50 +318,31 @@ func (s *Storage) GetUserReviews(userID string) ([]models.PullRequest, error) {
 
 // PR METHODS
 
-func (s *Storage) CreatePR(req models.CreatePRRequest) (*models.PullRequest, error) {
+func (s *Storage) CreatePR(ctx context.Context, req models.CreatePRRequest, actor string) (*models.PullRequest, error) {
 	const op = "storage.CreatePR"
 
-	var author models.User
-	err := s.db.QueryRow(`
-		SELECT user_id, username, team_name, is_active 
-		FROM users WHERE user_id = $1
-	`, req.AuthorID).Scan(&author.ID, &author.Username, &author.TeamName, &author.IsActive)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("%s: %w", op, ErrAuthorNotFound)
-	}
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer tx.Rollback()
 
-	rows, err := s.db.Query(`
-		SELECT user_id 
-		FROM users 
-		WHERE team_name = $1 AND is_active = true AND user_id != $2
-	`, author.TeamName, author.ID)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-	defer rows.Close()
-
-	var candidateIDs []string
-	for rows.Next() {
-		var userID string
-		if err := rows.Scan(&userID); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-		candidateIDs = append(candidateIDs, userID)
+	var author models.User
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active 
+		FROM users WHERE user_id = $1
+		FOR SHARE
+	`, req.AuthorID).Scan(&author.ID, &author.Username, &author.TeamName, &author.IsActive)
+	// участник, удалённый из команды, не может создавать PR
+	if err == sql.ErrNoRows || (err == nil && author.TeamName == "") {
+		return nil, fmt.Errorf("%s: %w", op, ErrAuthorNotFound)
 	}
-
-	reviewers := s.selectRandomReviewers(candidateIDs, 2)
-
-	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer tx.Rollback()
 
 	var prExists bool
-	err = tx.QueryRow(`
+	err = tx.QueryRowContext(ctx, `
 		SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)
 	`, req.ID).Scan(&prExists)
 	if err != nil {
@@ -246,69 +352,149 @@ func (s *Storage) CreatePR(req models.CreatePRRequest) (*models.PullRequest, err
 		return nil, fmt.Errorf("%s: %w", op, ErrPRExists)
 	}
 
-	now := time.Now()
-	_, err = tx.Exec(`
+	pr := &models.PullRequest{
+		ID:                req.ID,
+		Name:              req.Name,
+		AuthorID:          req.AuthorID,
+		Status:            models.StatusOpen,
+		AssignedReviewers: []string{},
+		CreatedAt:         time.Now(),
+	}
+	if req.Draft {
+		pr.Status = models.StatusDraft
+	}
+
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at)
 		VALUES ($1, $2, $3, $4, $5)
-	`, req.ID, req.Name, req.AuthorID, models.StatusOpen, now)
+	`, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt)
 	if err != nil {
+		// параллельный запрос мог создать PR после проверки выше
+		if apperr.IsUniqueViolation(err, "pull_requests_pkey") {
+			return nil, fmt.Errorf("%s: %w", op, ErrPRExists)
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	for _, reviewer := range reviewers {
-		_, err := tx.Exec(`
-			INSERT INTO pr_reviewers (pr_id, reviewer_id) VALUES ($1, $2)
-		`, req.ID, reviewer)
-		if err != nil {
+	if err := s.recordPREvents(ctx, tx, models.PREvent{PRID: pr.ID, Type: models.PREventCreated}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if pr.Status == models.StatusOpen {
+		if err := s.assignReviewers(ctx, tx, pr, author.TeamName); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
 	}
 
+	if err := s.recordAudit(ctx, tx, actor, models.AuditCreatePR, pr.ID, nil, pr); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	metrics.PRsCreated.Inc()
 
-	return &models.PullRequest{
-		ID:                req.ID,
-		Name:              req.Name,
-		AuthorID:          req.AuthorID,
-		Status:            models.StatusOpen,
-		AssignedReviewers: reviewers,
-		CreatedAt:         now,
-	}, nil
+	return pr, nil
 }
 
-func (s *Storage) MergePR(prID string) (*models.PullRequest, error) {
+func (s *Storage) MergePR(ctx context.Context, prID string, actor string) (*models.PullRequest, error) {
 	const op = "storage.MergePR"
 
-	pr, err := s.getPRWithReviewers(prID)
+	pr, err := s.mergePR(ctx, prID, actor, false)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	return pr, nil
+}
+
+// MergeExternalPR фиксирует merge, который уже произошёл в GitHub/GitLab. Это факт,
+// а не запрос: политика одобрений не проверяется, а PR переводится в MERGED из любого
+// статуса, даже если событие ready или reopen до сервиса не дошло.
+func (s *Storage) MergeExternalPR(ctx context.Context, prID string, actor string) (*models.PullRequest, error) {
+	const op = "storage.MergeExternalPR"
+
+	pr, err := s.mergePR(ctx, prID, actor, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pr, nil
+}
+
+func (s *Storage) mergePR(ctx context.Context, prID string, actor string, external bool) (*models.PullRequest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.lockPR(ctx, tx, prID); err != nil {
+		return nil, err
+	}
+
+	pr, err := s.getPRWithReviewers(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
 	if pr.Status == models.StatusMerged {
 		return pr, nil
 	}
+	if !external {
+		if !canTransition(pr.Status, models.StatusMerged) {
+			return nil, ErrInvalidTransition
+		}
+		if err := s.checkApprovals(ctx, tx, pr); err != nil {
+			return nil, err
+		}
+	}
 
 	now := time.Now()
-	_, err = s.db.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE pull_requests 
 		SET status = $1, merged_at = $2 
 		WHERE pull_request_id = $3
 	`, models.StatusMerged, now, prID)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, err
 	}
 
+	if err := s.recordPREvents(ctx, tx, models.PREvent{PRID: prID, Type: models.PREventMerged}); err != nil {
+		return nil, err
+	}
+
+	before := *pr
 	pr.Status = models.StatusMerged
 	pr.MergedAt = &now
+	if err := s.recordAudit(ctx, tx, actor, models.AuditMergePR, prID, before, pr); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	metrics.PRsMerged.Inc()
+
 	return pr, nil
 }
 
-func (s *Storage) ReassignReviewer(req models.ReassignRequest) (*models.PullRequest, string, error) {
+func (s *Storage) ReassignReviewer(ctx context.Context, req models.ReassignRequest, actor string) (*models.PullRequest, string, error) {
 	const op = "storage.ReassignReviewer"
 
-	pr, err := s.getPRWithReviewers(req.PRID)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// блокировка PR не даёт двум параллельным переназначениям прочитать один и тот же состав ревьюверов
+	if err := s.lockPR(ctx, tx, req.PRID); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	pr, err := s.getPRWithReviewers(ctx, tx, req.PRID)
 	if err != nil {
 		return nil, "", fmt.Errorf("%s: %w", op, err)
 	}
@@ -316,189 +502,506 @@ func (s *Storage) ReassignReviewer(req models.ReassignRequest) (*models.PullRequ
 	if pr.Status == models.StatusMerged {
 		return nil, "", fmt.Errorf("%s: %w", op, ErrPRMerged)
 	}
+	if pr.Status != models.StatusOpen {
+		return nil, "", fmt.Errorf("%s: %w", op, ErrPRNotOpen)
+	}
 
 	if !s.contains(pr.AssignedReviewers, req.OldReviewer) {
 		return nil, "", fmt.Errorf("%s: %w", op, ErrNotAssigned)
 	}
 
-	var oldReviewerTeam string
-	err = s.db.QueryRow(`
-		SELECT team_name FROM users WHERE user_id = $1
-	`, req.OldReviewer).Scan(&oldReviewerTeam)
+	before := *pr
+	newReviewer, err := s.replaceReviewer(ctx, tx, pr, req.OldReviewer, models.ReasonManual)
 	if err != nil {
+		if errors.Is(err, ErrNoCandidate) {
+			metrics.NoCandidate.WithLabelValues(metrics.SourceManual).Inc()
+		}
 		return nil, "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	candidateIDs, err := s.findReplacementCandidates(oldReviewerTeam, pr.AuthorID, pr.AssignedReviewers, req.OldReviewer)
-	if err != nil {
+	after := struct {
+		*models.PullRequest
+		ReplacedBy string `json:"replaced_by"`
+	}{pr, newReviewer}
+	if err := s.recordAudit(ctx, tx, actor, models.AuditReassignReviewer, pr.ID, before, after); err != nil {
 		return nil, "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	if len(candidateIDs) == 0 {
-		return nil, "", fmt.Errorf("%s: %w", op, ErrNoCandidate)
+	if err := tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
 	}
+	metrics.Reassignments.WithLabelValues(metrics.SourceManual).Inc()
+
+	return pr, newReviewer, nil
+}
 
-	newReviewer := s.selectRandomReviewer(candidateIDs)
+// replaceReviewer подбирает замену oldReviewer по политике команды автора и
+// обновляет pr_reviewers в рамках переданной транзакции. pr изменяется на месте.
+func (s *Storage) replaceReviewer(ctx context.Context, q querier, pr *models.PullRequest, oldReviewer, reason string) (string, error) {
+	const op = "storage.replaceReviewer"
 
-	tx, err := s.db.Begin()
+	var oldReviewerTeam string
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1
+	`, oldReviewer).Scan(&oldReviewerTeam)
 	if err != nil {
-		return nil, "", fmt.Errorf("%s: %w", op, err)
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
-	defer tx.Rollback()
 
-	_, err = tx.Exec(`
+	var authorTeam string
+	err = q.QueryRowContext(ctx, `
+		SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1
+	`, pr.AuthorID).Scan(&authorTeam)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	// ревьюверу, удалённому из команды, замена ищется в команде автора
+	if oldReviewerTeam == "" {
+		oldReviewerTeam = authorTeam
+	}
+
+	policy, err := s.getTeamPolicy(ctx, q, authorTeam)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var newReviewer string
+	var fromFallback bool
+	var skip []string
+	for {
+		// skip — кандидаты, которых параллельно деактивировали после чтения
+		current := append(append([]string{}, pr.AssignedReviewers...), skip...)
+		candidates, err := s.findReplacementCandidates(ctx, q, oldReviewerTeam, pr.AuthorID, current, oldReviewer)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		selected, err := s.selectReviewers(policy.Strategy, candidates, 1)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		fromFallback = oldReviewerTeam != authorTeam
+
+		if len(selected) == 0 && policy.AllowCrossTeam {
+			exclude := append([]string{pr.AuthorID}, current...)
+			selected, err = s.selectFromFallbackTeams(ctx, q, policy, exclude, 1)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", op, err)
+			}
+			fromFallback = true
+		}
+
+		if len(selected) == 0 {
+			return "", fmt.Errorf("%s: %w", op, ErrNoCandidate)
+		}
+
+		inactive, err := s.lockActiveUsers(ctx, q, selected)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		if len(inactive) == 0 {
+			newReviewer = selected[0]
+			break
+		}
+		skip = append(skip, inactive...)
+	}
+
+	_, err = q.ExecContext(ctx, `
 		DELETE FROM pr_reviewers 
 		WHERE pr_id = $1 AND reviewer_id = $2
-	`, req.PRID, req.OldReviewer)
+	`, pr.ID, oldReviewer)
 	if err != nil {
-		return nil, "", fmt.Errorf("%s: %w", op, err)
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	_, err = tx.Exec(`
-		INSERT INTO pr_reviewers (pr_id, reviewer_id) VALUES ($1, $2)
-	`, req.PRID, newReviewer)
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO pr_reviewers (pr_id, reviewer_id, from_fallback) VALUES ($1, $2, $3)
+	`, pr.ID, newReviewer, fromFallback)
 	if err != nil {
-		return nil, "", fmt.Errorf("%s: %w", op, err)
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, "", fmt.Errorf("%s: %w", op, err)
+	_, err = q.ExecContext(ctx, `
+		UPDATE pull_requests SET assignment_strategy = $1 WHERE pull_request_id = $2
+	`, policy.Strategy, pr.ID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	newReviewers := s.replaceInSlice(pr.AssignedReviewers, req.OldReviewer, newReviewer)
-	pr.AssignedReviewers = newReviewers
+	err = s.recordPREvents(ctx, q, models.PREvent{
+		PRID:          pr.ID,
+		Type:          models.PREventReassigned,
+		OldReviewerID: oldReviewer,
+		NewReviewerID: newReviewer,
+		Reason:        reason,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
 
-	return pr, newReviewer, nil
+	pr.AssignedReviewers = s.replaceInSlice(pr.AssignedReviewers, oldReviewer, newReviewer)
+	pr.FallbackReviewers = s.removeFromSlice(pr.FallbackReviewers, oldReviewer)
+	if fromFallback {
+		pr.FallbackReviewers = append(pr.FallbackReviewers, newReviewer)
+	}
+	pr.Strategy = policy.Strategy
+
+	return newReviewer, nil
 }
 
 // HELPER METHODS
 
-func (s *Storage) getPRWithReviewers(prID string) (*models.PullRequest, error) {
+// getUsers возвращает уже существующих пользователей из переданного списка
+func (s *Storage) getUsers(ctx context.Context, q querier, members []models.User) ([]models.User, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(members))
+	for i, member := range members {
+		ids[i] = member.ID
+	}
+	ids = unique(ids)
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active, review_weight
+		FROM users
+		WHERE user_id IN (`+placeholders(1, len(ids))+`)
+		ORDER BY user_id
+	`, stringArgs(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Weight); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (s *Storage) getPRWithReviewers(ctx context.Context, q querier, prID string) (*models.PullRequest, error) {
 	const op = "storage.getPRWithReviewers"
 
 	var pr models.PullRequest
 	var statusStr string
-	var mergedAt sql.NullTime
+	var mergedAt, closedAt sql.NullTime
+	var strategy sql.NullString
 
-	err := s.db.QueryRow(`
+	err := q.QueryRowContext(ctx, `
 		SELECT 
 			pull_request_id, pull_request_name, author_id, status, 
-			created_at, merged_at
+			assignment_strategy, created_at, merged_at, closed_at
 		FROM pull_requests 
 		WHERE pull_request_id = $1
 	`, prID).Scan(
 		&pr.ID, &pr.Name, &pr.AuthorID, &statusStr,
-		&pr.CreatedAt, &mergedAt,
+		&strategy, &pr.CreatedAt, &mergedAt, &closedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("%s: %w", op, ErrNotFound)
+		return nil, fmt.Errorf("%s: %w", op, ErrPRNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	pr.Status = models.PRStatus(statusStr)
+	pr.Strategy = models.AssignmentStrategy(strategy.String)
 	if mergedAt.Valid {
 		pr.MergedAt = &mergedAt.Time
 	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
 
-	reviewers, err := s.getPRReviewers(prID)
+	reviewers, fallbackReviewers, err := s.getPRReviewers(ctx, q, prID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	pr.AssignedReviewers = reviewers
+	pr.FallbackReviewers = fallbackReviewers
 
 	return &pr, nil
 }
 
-func (s *Storage) getPRReviewers(prID string) ([]string, error) {
+func (s *Storage) getPRReviewers(ctx context.Context, q querier, prID string) ([]string, []string, error) {
 	const op = "storage.getPRReviewers"
 
-	rows, err := s.db.Query(`
-		SELECT reviewer_id 
+	rows, err := q.QueryContext(ctx, `
+		SELECT reviewer_id, from_fallback 
 		FROM pr_reviewers 
 		WHERE pr_id = $1
 		ORDER BY assigned_at
 	`, prID)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	reviewers := []string{}
+	var fallbackReviewers []string
 	for rows.Next() {
 		var reviewerID string
-		if err := rows.Scan(&reviewerID); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+		var fromFallback bool
+		if err := rows.Scan(&reviewerID, &fromFallback); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
 		}
 		reviewers = append(reviewers, reviewerID)
+		if fromFallback {
+			fallbackReviewers = append(fallbackReviewers, reviewerID)
+		}
 	}
 
-	return reviewers, nil
+	return reviewers, fallbackReviewers, nil
 }
 
-func (s *Storage) findReplacementCandidates(teamName, authorID string, currentReviewers []string, excludeReviewer string) ([]string, error) {
-	query := `
-		SELECT user_id 
-		FROM users 
-		WHERE team_name = $1 
-		AND is_active = true 
-		AND user_id != $2 
-		AND user_id != $3
-	`
+// assignReviewers подбирает и сохраняет ревьюверов для PR по политике команды автора
+func (s *Storage) assignReviewers(ctx context.Context, q querier, pr *models.PullRequest, authorTeam string) error {
+	policy, err := s.getTeamPolicy(ctx, q, authorTeam)
+	if err != nil {
+		return err
+	}
 
-	params := []interface{}{teamName, authorID, excludeReviewer}
-	paramCount := 4
+	exclude := []string{pr.AuthorID}
+	var reviewers, fallbackReviewers []string
+	for {
+		reviewers, fallbackReviewers, err = s.pickReviewers(ctx, q, policy, authorTeam, exclude)
+		if err != nil {
+			return err
+		}
 
-	for _, reviewer := range currentReviewers {
-		if reviewer != excludeReviewer {
-			query += fmt.Sprintf(" AND user_id != $%d", paramCount)
-			params = append(params, reviewer)
-			paramCount++
+		// выбранного кандидата могли деактивировать параллельно: исключаем его и выбираем заново
+		inactive, err := s.lockActiveUsers(ctx, q, reviewers)
+		if err != nil {
+			return err
+		}
+		if len(inactive) == 0 {
+			break
+		}
+		exclude = append(exclude, inactive...)
+	}
+
+	if len(reviewers) < policy.MinReviewers {
+		return ErrNotEnoughReviewers
+	}
+
+	events := make([]models.PREvent, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO pr_reviewers (pr_id, reviewer_id, from_fallback) VALUES ($1, $2, $3)
+		`, pr.ID, reviewer, s.contains(fallbackReviewers, reviewer))
+		if err != nil {
+			return err
+		}
+		events = append(events, models.PREvent{PRID: pr.ID, Type: models.PREventAssigned, ReviewerID: reviewer})
+	}
+
+	if err := s.recordPREvents(ctx, q, events...); err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx, `
+		UPDATE pull_requests SET assignment_strategy = $1 WHERE pull_request_id = $2
+	`, policy.Strategy, pr.ID)
+	if err != nil {
+		return err
+	}
+
+	pr.AssignedReviewers = reviewers
+	pr.FallbackReviewers = fallbackReviewers
+	pr.Strategy = policy.Strategy
+	return nil
+}
+
+// pickReviewers выбирает до policy.MaxReviewers ревьюверов из команды автора,
+// добирая недостающих из резервных команд, если политика это разрешает
+func (s *Storage) pickReviewers(ctx context.Context, q querier, policy *models.TeamPolicy, authorTeam string, exclude []string) ([]string, []string, error) {
+	candidates, err := s.findCandidates(ctx, q, authorTeam, exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reviewers, err := s.selectReviewers(policy.Strategy, candidates, policy.MaxReviewers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fallbackReviewers []string
+	if len(reviewers) < policy.MaxReviewers && policy.AllowCrossTeam {
+		skip := append(append([]string{}, exclude...), reviewers...)
+		fallbackReviewers, err = s.selectFromFallbackTeams(ctx, q, policy, skip, policy.MaxReviewers-len(reviewers))
+		if err != nil {
+			return nil, nil, err
 		}
+		reviewers = append(reviewers, fallbackReviewers...)
 	}
 
-	rows, err := s.db.Query(query, params...)
+	return reviewers, fallbackReviewers, nil
+}
+
+// lockPR блокирует строку PR до конца транзакции, чтобы изменения одного PR
+// (переназначение, merge, смена статуса, SLA) выполнялись строго по очереди
+func (s *Storage) lockPR(ctx context.Context, q querier, prID string) error {
+	var locked int
+	err := q.QueryRowContext(ctx, `
+		SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE
+	`, prID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return ErrPRNotFound
+	}
+	return err
+}
+
+// lockActiveUsers берёт разделяемую блокировку на выбранных ревьюверов и возвращает тех,
+// кто уже неактивен. Пока блокировка держится, деактивация пользователя ждёт конца транзакции
+// и затем переназначит его ревью, включая только что созданные.
+func (s *Storage) lockActiveUsers(ctx context.Context, q querier, userIDs []string) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := unique(userIDs)
+	// фильтр по is_active не в WHERE: строка, изменённая параллельно, перечитывается уже после ожидания
+	rows, err := q.QueryContext(ctx, `
+		SELECT user_id, is_active FROM users
+		WHERE user_id IN (`+placeholders(1, len(ids))+`)
+		ORDER BY user_id
+		FOR SHARE
+	`, stringArgs(ids)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var candidates []string
+	found := make(map[string]bool, len(ids))
+	var inactive []string
 	for rows.Next() {
 		var userID string
-		if err := rows.Scan(&userID); err != nil {
+		var isActive bool
+		if err := rows.Scan(&userID, &isActive); err != nil {
 			return nil, err
 		}
-		candidates = append(candidates, userID)
+		found[userID] = true
+		if !isActive {
+			inactive = append(inactive, userID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return candidates, nil
+	for _, id := range ids {
+		if !found[id] {
+			inactive = append(inactive, id)
+		}
+	}
+	return inactive, nil
 }
 
-func (s *Storage) selectRandomReviewers(candidates []string, max int) []string {
-	if len(candidates) == 0 {
-		return []string{}
+func (s *Storage) findReplacementCandidates(ctx context.Context, q querier, teamName, authorID string, currentReviewers []string, excludeReviewer string) ([]selector.Candidate, error) {
+	exclude := []string{authorID, excludeReviewer}
+	for _, reviewer := range currentReviewers {
+		if reviewer != excludeReviewer {
+			exclude = append(exclude, reviewer)
+		}
 	}
 
-	shuffled := make([]string, len(candidates))
-	copy(shuffled, candidates)
+	return s.findCandidates(ctx, q, teamName, exclude)
+}
 
-	s.rng.Shuffle(len(shuffled), func(i, j int) {
-		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
-	})
+func (s *Storage) findCandidates(ctx context.Context, q querier, teamName string, exclude []string) ([]selector.Candidate, error) {
+	where := `
+		WHERE u.team_name = $1 
+		AND u.is_active = true
+	`
 
-	if len(shuffled) <= max {
-		return shuffled
+	params := []interface{}{teamName}
+	paramCount := 2
+
+	for _, userID := range exclude {
+		where += fmt.Sprintf(" AND u.user_id != $%d", paramCount)
+		params = append(params, userID)
+		paramCount++
 	}
-	return shuffled[:max]
+
+	return s.loadCandidates(ctx, q, where, params...)
 }
 
-func (s *Storage) selectRandomReviewer(candidates []string) string {
-	if len(candidates) == 0 {
-		return ""
+// selectFromFallbackTeams добирает до n ревьюверов из резервных команд в порядке их приоритета
+func (s *Storage) selectFromFallbackTeams(ctx context.Context, q querier, policy *models.TeamPolicy, exclude []string, n int) ([]string, error) {
+	selected := []string{}
+
+	for _, team := range policy.FallbackTeams {
+		if len(selected) >= n {
+			break
+		}
+
+		skip := make([]string, 0, len(exclude)+len(selected))
+		skip = append(skip, exclude...)
+		skip = append(skip, selected...)
+
+		candidates, err := s.findCandidates(ctx, q, team, skip)
+		if err != nil {
+			return nil, err
+		}
+
+		picked, err := s.selectReviewers(policy.Strategy, candidates, n-len(selected))
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, picked...)
+	}
+
+	return selected, nil
+}
+
+func (s *Storage) loadCandidates(ctx context.Context, q querier, where string, params ...interface{}) ([]selector.Candidate, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT u.user_id, u.review_weight, MAX(prr.assigned_at), COUNT(pr.pull_request_id)
+		FROM users u
+		LEFT JOIN pr_reviewers prr ON prr.reviewer_id = u.user_id
+		LEFT JOIN pull_requests pr ON pr.pull_request_id = prr.pr_id AND pr.status = 'OPEN'
+	`+where+`
+		AND NOT EXISTS (
+			SELECT 1 FROM user_ooo o
+			WHERE o.user_id = u.user_id AND o.starts_at <= NOW() AND o.ends_at > NOW()
+		)
+		GROUP BY u.user_id, u.review_weight
+		ORDER BY u.user_id
+	`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []selector.Candidate
+	for rows.Next() {
+		var c selector.Candidate
+		var lastAssignedAt nullTime
+		if err := rows.Scan(&c.UserID, &c.Weight, &lastAssignedAt, &c.OpenReviews); err != nil {
+			return nil, err
+		}
+		if lastAssignedAt.Valid {
+			c.LastAssignedAt = &lastAssignedAt.Time
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+func (s *Storage) selectReviewers(strategy models.AssignmentStrategy, candidates []selector.Candidate, max int) ([]string, error) {
+	sel, err := selector.New(strategy, s.rng)
+	if err != nil {
+		return nil, err
 	}
-	return candidates[s.rng.Intn(len(candidates))]
+	return sel.Select(candidates, max), nil
 }
 
 func (s *Storage) contains(slice []string, item string) bool {
@@ -510,6 +1013,16 @@ func (s *Storage) contains(slice []string, item string) bool {
 	return false
 }
 
+func (s *Storage) removeFromSlice(slice []string, item string) []string {
+	result := make([]string, 0, len(slice))
+	for _, s := range slice {
+		if s != item {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
 func (s *Storage) replaceInSlice(slice []string, old, new string) []string {
 	result := make([]string, len(slice))
 	for i, item := range slice {
@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+// TestCreateTeamWeights проверяет, что нулевой вес сохраняется, а не переданный
+// вес не сбрасывает уже заданный при переносе участника в новую команду
+func TestCreateTeamWeights(t *testing.T) {
+	weight := func(w int) *int { return &w }
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := backend.open(t)
+
+			err := repo.CreateTeam(ctx, models.Team{Name: "core", Strategy: models.StrategyWeighted, Members: []models.User{
+				{ID: "author", Username: "author", IsActive: true},
+				{ID: "zero", Username: "zero", IsActive: true, Weight: weight(0)},
+				{ID: "heavy", Username: "heavy", IsActive: true, Weight: weight(3)},
+				{ID: "plain", Username: "plain", IsActive: true},
+			}}, "test")
+			if err != nil {
+				t.Fatalf("create core: %v", err)
+			}
+			checkWeights(t, repo, "core", map[string]int{"author": 1, "zero": 0, "heavy": 3, "plain": 1})
+
+			// участник с весом 0 не назначается стратегией weighted
+			for i := range 10 {
+				pr, err := repo.CreatePR(ctx, models.CreatePRRequest{ID: fmt.Sprintf("pr-%d", i), Name: "x", AuthorID: "author"}, "test")
+				if err != nil {
+					t.Fatalf("create PR: %v", err)
+				}
+				for _, reviewer := range pr.AssignedReviewers {
+					if reviewer == "zero" {
+						t.Fatalf("PR %s assigned to zero-weight member", pr.ID)
+					}
+				}
+			}
+
+			err = repo.CreateTeam(ctx, models.Team{Name: "platform", Members: []models.User{
+				{ID: "zero", Username: "zero", IsActive: true},
+				{ID: "heavy", Username: "heavy", IsActive: true},
+				{ID: "plain", Username: "plain", IsActive: true, Weight: weight(5)},
+			}}, "test")
+			if err != nil {
+				t.Fatalf("create platform: %v", err)
+			}
+			checkWeights(t, repo, "platform", map[string]int{"zero": 0, "heavy": 3, "plain": 5})
+
+			err = repo.CreateTeam(ctx, models.Team{Name: "broken", Members: []models.User{
+				{ID: "negative", Username: "negative", IsActive: true, Weight: weight(-1)},
+			}}, "test")
+			if !errors.Is(err, storage.ErrInvalidWeight) {
+				t.Errorf("negative weight: got %v, want %v", err, storage.ErrInvalidWeight)
+			}
+		})
+	}
+}
+
+func checkWeights(t *testing.T, repo storage.Repository, teamName string, want map[string]int) {
+	t.Helper()
+
+	team, err := repo.GetTeam(context.Background(), teamName)
+	if err != nil {
+		t.Fatalf("get team %s: %v", teamName, err)
+	}
+	for _, member := range team.Members {
+		if member.Weight == nil || *member.Weight != want[member.ID] {
+			t.Errorf("team %s: %s has weight %v, want %d", teamName, member.ID, member.Weight, want[member.ID])
+		}
+	}
+}
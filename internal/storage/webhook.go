@@ -0,0 +1,458 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"review-assignment/internal/models"
+)
+
+var webhookEventTypes = map[models.PREventType]bool{
+	models.PREventCreated:    true,
+	models.PREventAssigned:   true,
+	models.PREventReassigned: true,
+	models.PREventReviewed:   true,
+	models.PREventReady:      true,
+	models.PREventClosed:     true,
+	models.PREventReopened:   true,
+	models.PREventMerged:     true,
+	models.PREventUnassigned: true,
+}
+
+// WEBHOOK METHODS
+
+func (s *Storage) AddWebhook(ctx context.Context, req models.AddWebhookRequest) (*models.WebhookSubscription, error) {
+	const op = "storage.AddWebhook"
+
+	eventTypes, err := validateWebhook(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if req.TeamName != "" {
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM teams WHERE name = $1)
+		`, req.TeamName).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("%s: %w", op, ErrTeamNotFound)
+		}
+	}
+
+	sub := &models.WebhookSubscription{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: make([]models.PREventType, len(eventTypes)),
+		TeamName:   req.TeamName,
+	}
+	for i, eventType := range eventTypes {
+		sub.EventTypes[i] = models.PREventType(eventType)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, team_name)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at
+	`, sub.URL, sub.Secret, strings.Join(eventTypes, ","), sub.TeamName).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sub, nil
+}
+
+func (s *Storage) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	const op = "storage.ListWebhooks"
+
+	subs, err := s.loadWebhookSubscriptions(ctx, s.db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subs, nil
+}
+
+func (s *Storage) RemoveWebhook(ctx context.Context, id int64) error {
+	const op = "storage.RemoveWebhook"
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries возвращает последние доставки, при необходимости по подписке и статусу
+func (s *Storage) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status models.WebhookDeliveryStatus, limit int) ([]models.WebhookDelivery, error) {
+	const op = "storage.ListWebhookDeliveries"
+
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, subscription_id, event_type, payload, status, attempts,
+			next_attempt_at, last_error, created_at, delivered_at
+		FROM webhook_outbox
+		WHERE ($1 = 0 OR subscription_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`, subscriptionID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		deliveries = append(deliveries, *d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RedeliverWebhook возвращает доставку в очередь с обнулённым счётчиком попыток,
+// в том числе из dead-letter и уже доставленные
+func (s *Storage) RedeliverWebhook(ctx context.Context, deliveryID int64) (*models.WebhookDelivery, error) {
+	const op = "storage.RedeliverWebhook"
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE webhook_outbox
+		SET status = $1, attempts = 0, next_attempt_at = NOW(), last_error = '', delivered_at = NULL
+		WHERE id = $2
+		RETURNING id, subscription_id, event_type, payload, status, attempts,
+			next_attempt_at, last_error, created_at, delivered_at
+	`, models.DeliveryPending, deliveryID)
+
+	d, err := scanWebhookDelivery(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", op, ErrDeliveryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+// ClaimWebhookDeliveries выдаёт диспетчеру готовые к отправке доставки и откладывает их на lease,
+// чтобы параллельные реплики не отправили одно и то же событие одновременно
+func (s *Storage) ClaimWebhookDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	const op = "storage.ClaimWebhookDeliveries"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT o.id, o.subscription_id, o.event_type, o.payload, o.status, o.attempts,
+			o.next_attempt_at, o.last_error, o.created_at, o.delivered_at, ws.url, ws.secret
+		FROM webhook_outbox o
+		JOIN webhook_subscriptions ws ON ws.id = o.subscription_id
+		WHERE o.status = $1 AND o.next_attempt_at <= $2
+		ORDER BY o.next_attempt_at, o.id
+		LIMIT $3
+		FOR UPDATE OF o SKIP LOCKED
+	`, models.DeliveryPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	var ids []interface{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var eventType, status string
+		var payload []byte
+		var deliveredAt sql.NullTime
+
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &eventType, &payload, &status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &deliveredAt, &d.URL, &d.Secret); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		d.EventType = models.PREventType(eventType)
+		d.Status = models.WebhookDeliveryStatus(status)
+		d.Payload = payload
+
+		deliveries = append(deliveries, d)
+		ids = append(ids, d.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_outbox SET next_attempt_at = $1
+		WHERE id IN (`+placeholders(2, len(ids))+`)
+	`, append([]interface{}{now.Add(lease)}, ids...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func (s *Storage) MarkWebhookDelivered(ctx context.Context, deliveryID int64) error {
+	const op = "storage.MarkWebhookDelivered"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_outbox
+		SET status = $1, attempts = attempts + 1, last_error = '', delivered_at = NOW()
+		WHERE id = $2
+	`, models.DeliveryDelivered, deliveryID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkWebhookFailed фиксирует неудачную попытку. Если retryAt == nil, доставка уходит в dead-letter.
+func (s *Storage) MarkWebhookFailed(ctx context.Context, deliveryID int64, lastError string, retryAt *time.Time) error {
+	const op = "storage.MarkWebhookFailed"
+
+	status := models.DeliveryPending
+	nextAttemptAt := time.Now()
+	if retryAt == nil {
+		status = models.DeliveryDead
+	} else {
+		nextAttemptAt = *retryAt
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE webhook_outbox
+		SET status = $1, attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $4
+	`, status, lastError, nextAttemptAt, deliveryID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// enqueueWebhooks кладёт события в outbox для всех подходящих подписок в рамках переданной транзакции:
+// событие уходит подписчикам только если изменение, которое его породило, зафиксировано
+func (s *Storage) enqueueWebhooks(ctx context.Context, q querier, events []models.PREvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	subs, err := s.loadWebhookSubscriptions(ctx, q)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	prIDs := make([]string, len(events))
+	for i, e := range events {
+		prIDs[i] = e.PRID
+	}
+	prIDs = unique(prIDs)
+
+	teams := make(map[string]string, len(prIDs))
+	for _, batch := range chunks(prIDs, batchSize) {
+		rows, err := q.QueryContext(ctx, `
+			SELECT pr.pull_request_id, COALESCE(u.team_name, '')
+			FROM pull_requests pr
+			JOIN users u ON u.user_id = pr.author_id
+			WHERE pr.pull_request_id IN (`+placeholders(1, len(batch))+`)
+		`, stringArgs(batch)...)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var prID, team string
+			if err := rows.Scan(&prID, &team); err != nil {
+				rows.Close()
+				return err
+			}
+			teams[prID] = team
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	var values []string
+	var params []interface{}
+	flush := func() error {
+		if len(values) == 0 {
+			return nil
+		}
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO webhook_outbox (subscription_id, event_type, payload, next_attempt_at)
+			VALUES `+strings.Join(values, ", "), params...)
+		values, params = values[:0], params[:0]
+		return err
+	}
+
+	for _, e := range events {
+		team := teams[e.PRID]
+		var payload []byte
+
+		for _, sub := range subs {
+			if !webhookMatches(sub, e.Type, team) {
+				continue
+			}
+
+			if payload == nil {
+				payload, err = json.Marshal(models.WebhookEvent{
+					Type:          e.Type,
+					TeamName:      team,
+					PRID:          e.PRID,
+					ReviewerID:    e.ReviewerID,
+					OldReviewerID: e.OldReviewerID,
+					NewReviewerID: e.NewReviewerID,
+					State:         e.State,
+					Reason:        e.Reason,
+					OccurredAt:    now,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			n := len(params)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+			params = append(params, sub.ID, e.Type, string(payload), now)
+
+			if len(values) == batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return flush()
+}
+
+// validateWebhook проверяет URL подписки и возвращает её типы событий без повторов
+func validateWebhook(req models.AddWebhookRequest) ([]string, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrInvalidWebhook
+	}
+
+	eventTypes := make([]string, 0, len(req.EventTypes))
+	for _, eventType := range req.EventTypes {
+		if !webhookEventTypes[eventType] {
+			return nil, ErrInvalidWebhook
+		}
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	return unique(eventTypes), nil
+}
+
+func webhookMatches(sub models.WebhookSubscription, eventType models.PREventType, team string) bool {
+	if sub.TeamName != "" && sub.TeamName != team {
+		return false
+	}
+	if len(sub.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range sub.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Storage) loadWebhookSubscriptions(ctx context.Context, q querier) ([]models.WebhookSubscription, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, url, secret, event_types, COALESCE(team_name, ''), created_at
+		FROM webhook_subscriptions
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		var eventTypes string
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Secret, &eventTypes, &sub.TeamName, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		sub.EventTypes = []models.PREventType{}
+		if eventTypes != "" {
+			for _, t := range strings.Split(eventTypes, ",") {
+				sub.EventTypes = append(sub.EventTypes, models.PREventType(t))
+			}
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// rowScanner — общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var eventType, status string
+	var payload []byte
+	var deliveredAt sql.NullTime
+
+	if err := row.Scan(&d.ID, &d.SubscriptionID, &eventType, &payload, &status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &deliveredAt); err != nil {
+		return nil, err
+	}
+
+	d.EventType = models.PREventType(eventType)
+	d.Status = models.WebhookDeliveryStatus(status)
+	d.Payload = payload
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &d, nil
+}
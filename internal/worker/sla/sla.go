@@ -0,0 +1,79 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/storage"
+)
+
+// Actor — инициатор изменений воркера в журнале аудита
+const Actor = "system:sla"
+
+// Worker периодически обрабатывает ревью с истёкшим SLA
+type Worker struct {
+	storage  storage.PRRepository
+	log      *slog.Logger
+	interval time.Duration
+}
+
+func New(storage storage.PRRepository, log *slog.Logger, interval time.Duration) *Worker {
+	return &Worker{
+		storage:  storage,
+		log:      log,
+		interval: interval,
+	}
+}
+
+// Run блокируется до отмены ctx
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.log.Info("sla worker started", slog.Duration("interval", w.interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Info("sla worker stopped")
+			return
+		case now := <-ticker.C:
+			w.tick(ctx, now)
+		}
+	}
+}
+
+func (w *Worker) tick(ctx context.Context, now time.Time) {
+	events, err := w.storage.ProcessOverdueReviews(ctx, now, Actor)
+	for _, event := range events {
+		w.log.Info("overdue review processed",
+			slog.String("pr_id", event.PRID),
+			slog.String("reviewer_id", event.ReviewerID),
+			slog.String("type", string(event.Type)),
+			slog.String("new_reviewer_id", event.NewReviewerID))
+	}
+	if err == nil {
+		return
+	}
+
+	// ошибки отдельных ревью не прерывают проход и логируются по одной
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		w.log.Error("failed to process overdue reviews", sl.Err(err))
+		return
+	}
+	for _, err := range joined.Unwrap() {
+		var reviewErr *storage.OverdueReviewError
+		if errors.As(err, &reviewErr) {
+			w.log.Error("failed to process overdue review",
+				slog.String("pr_id", reviewErr.PRID),
+				slog.String("reviewer_id", reviewErr.ReviewerID),
+				sl.Err(reviewErr.Err))
+			continue
+		}
+		w.log.Error("failed to process overdue reviews", sl.Err(err))
+	}
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"review-assignment/internal/lib/hmacsig"
+	"review-assignment/internal/lib/logger/sl"
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature-256"
+
+	maxErrorLength = 500
+)
+
+type Config struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// Worker доставляет события из outbox подписчикам
+type Worker struct {
+	storage storage.WebhookRepository
+	client  *http.Client
+	log     *slog.Logger
+	cfg     Config
+}
+
+// New создаёт диспетчер. client можно подменить, например, для отправки на httptest-сервер.
+func New(storage storage.WebhookRepository, client *http.Client, log *slog.Logger, cfg Config) *Worker {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return &Worker{
+		storage: storage,
+		client:  client,
+		log:     log,
+		cfg:     cfg,
+	}
+}
+
+// Run блокируется до отмены ctx
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	w.log.Info("webhook dispatcher started", slog.Duration("interval", w.cfg.Interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Info("webhook dispatcher stopped")
+			return
+		case now := <-ticker.C:
+			w.Dispatch(ctx, now)
+		}
+	}
+}
+
+// Dispatch отправляет одну пачку готовых доставок и возвращает их количество
+func (w *Worker) Dispatch(ctx context.Context, now time.Time) int {
+	// пока идёт отправка, доставки не должны достаться другой реплике
+	lease := w.cfg.Timeout + w.cfg.Interval
+
+	deliveries, err := w.storage.ClaimWebhookDeliveries(ctx, now, w.cfg.BatchSize, lease)
+	if err != nil {
+		w.log.Error("failed to claim webhook deliveries", sl.Err(err))
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func(d models.WebhookDelivery) {
+			defer wg.Done()
+			w.deliver(ctx, d)
+		}(d)
+	}
+	wg.Wait()
+
+	return len(deliveries)
+}
+
+func (w *Worker) deliver(ctx context.Context, d models.WebhookDelivery) {
+	log := w.log.With(slog.Int64("delivery_id", d.ID), slog.Int64("subscription_id", d.SubscriptionID))
+	// результат попытки фиксируется и при остановке воркера, иначе доставка уйдёт повторно только после lease
+	storeCtx := context.WithoutCancel(ctx)
+
+	sendErr := w.send(ctx, d)
+	if sendErr == nil {
+		if err := w.storage.MarkWebhookDelivered(storeCtx, d.ID); err != nil {
+			log.Error("failed to mark webhook delivered", sl.Err(err))
+		}
+		return
+	}
+
+	attempts := d.Attempts + 1
+	var retryAt *time.Time
+	if attempts < w.cfg.MaxAttempts {
+		next := time.Now().Add(w.backoff(attempts))
+		retryAt = &next
+	}
+
+	message := sendErr.Error()
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+	if err := w.storage.MarkWebhookFailed(storeCtx, d.ID, message, retryAt); err != nil {
+		log.Error("failed to mark webhook failed", sl.Err(err))
+		return
+	}
+
+	if retryAt == nil {
+		log.Warn("webhook moved to dead letter", slog.Int("attempts", attempts), sl.Err(sendErr))
+		return
+	}
+	log.Info("webhook delivery failed, will retry",
+		slog.Int("attempts", attempts), slog.Time("retry_at", *retryAt), sl.Err(sendErr))
+}
+
+func (w *Worker) send(ctx context.Context, d models.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "review-assignment-webhooks")
+	req.Header.Set(HeaderEvent, string(d.EventType))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderSignature, hmacsig.Sign(d.Secret, d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff растёт экспоненциально: BaseBackoff, 2×BaseBackoff, 4×BaseBackoff... но не больше MaxBackoff
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.cfg.MaxBackoff {
+			return w.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,203 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"review-assignment/internal/api/webhook_handler"
+	"review-assignment/internal/lib/businesstime"
+	"review-assignment/internal/lib/hmacsig"
+	"review-assignment/internal/models"
+	"review-assignment/internal/storage"
+	"review-assignment/internal/worker/webhook"
+)
+
+const secret = "s3cret"
+
+// receiver — локальный подписчик, который запоминает запросы и отвечает заданным статусом
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []received
+}
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, received{header: req.Header.Clone(), body: body})
+	w.WriteHeader(r.status)
+}
+
+func (r *receiver) respond(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *receiver) received() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received(nil), r.requests...)
+}
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// setup поднимает хранилище с одной подпиской на httptest-сервер и одной доставкой события CREATED
+func setup(t *testing.T, cfg webhook.Config) (*storage.Memory, *receiver, *webhook.Worker, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	recv := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(recv)
+	t.Cleanup(server.Close)
+
+	repo := storage.NewMemory(businesstime.Default())
+	_, err := repo.AddWebhook(ctx, models.AddWebhookRequest{
+		URL:        server.URL,
+		Secret:     secret,
+		EventTypes: []models.PREventType{models.PREventCreated},
+	})
+	if err != nil {
+		t.Fatalf("add webhook: %v", err)
+	}
+
+	team := models.Team{Name: "core"}
+	for _, id := range []string{"u1", "u2", "u3"} {
+		team.Members = append(team.Members, models.User{ID: id, Username: id, IsActive: true})
+	}
+	if err := repo.CreateTeam(ctx, team, "test"); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	if _, err := repo.CreatePR(ctx, models.CreatePRRequest{ID: "pr-1", Name: "x", AuthorID: "u1"}, "test"); err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+
+	deliveries, err := repo.ListWebhookDeliveries(ctx, 0, "", 0)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, %v; want 1", len(deliveries), err)
+	}
+
+	return repo, recv, webhook.New(repo, server.Client(), discard, cfg), deliveries[0].ID
+}
+
+func getDelivery(t *testing.T, repo storage.WebhookRepository, id int64) models.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := repo.ListWebhookDeliveries(context.Background(), 0, "", 0)
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	for _, d := range deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	t.Fatalf("delivery %d not found", id)
+	return models.WebhookDelivery{}
+}
+
+func TestDispatchSignsBody(t *testing.T) {
+	repo, recv, worker, id := setup(t, webhook.Config{BatchSize: 10, MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour, Timeout: time.Second})
+
+	if n := worker.Dispatch(context.Background(), time.Now()); n != 1 {
+		t.Fatalf("dispatched %d deliveries, want 1", n)
+	}
+
+	requests := recv.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if got, want := req.header.Get(webhook.HeaderSignature), hmacsig.Sign(secret, req.body); got != want {
+		t.Errorf("signature %q, want %q", got, want)
+	}
+	if !hmacsig.Verify(secret, req.body, req.header.Get(webhook.HeaderSignature)) {
+		t.Error("signature does not verify against the received body")
+	}
+	if got := req.header.Get(webhook.HeaderEvent); got != string(models.PREventCreated) {
+		t.Errorf("event header %q, want %q", got, models.PREventCreated)
+	}
+	if got := req.header.Get(webhook.HeaderDelivery); got != strconv.FormatInt(id, 10) {
+		t.Errorf("delivery header %q, want %d", got, id)
+	}
+
+	if d := getDelivery(t, repo, id); d.Status != models.DeliveryDelivered || d.Attempts != 1 {
+		t.Errorf("delivery is %s after %d attempts, want DELIVERED after 1", d.Status, d.Attempts)
+	}
+}
+
+func TestDispatchRetriesAndDeadLetters(t *testing.T) {
+	const maxAttempts = 4
+	base := time.Minute
+	repo, recv, worker, id := setup(t, webhook.Config{BatchSize: 10, MaxAttempts: maxAttempts, BaseBackoff: base, MaxBackoff: time.Hour, Timeout: time.Second})
+	recv.respond(http.StatusInternalServerError)
+
+	// время диспетчера уходит вперёд, чтобы каждая отложенная попытка уже наступила
+	now := time.Now()
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		now = now.Add(24 * time.Hour)
+		before := time.Now()
+		worker.Dispatch(context.Background(), now)
+
+		d := getDelivery(t, repo, id)
+		if d.Status != models.DeliveryPending || d.Attempts != attempt {
+			t.Fatalf("attempt %d: delivery is %s after %d attempts, want PENDING", attempt, d.Status, d.Attempts)
+		}
+		want := base << (attempt - 1)
+		if delay := d.NextAttemptAt.Sub(before); delay < want || delay > want+5*time.Second {
+			t.Errorf("attempt %d: retry in %s, want %s", attempt, delay, want)
+		}
+	}
+
+	worker.Dispatch(context.Background(), now.Add(24*time.Hour))
+	d := getDelivery(t, repo, id)
+	if d.Status != models.DeliveryDead || d.Attempts != maxAttempts || d.LastError == "" {
+		t.Fatalf("delivery is %s after %d attempts (last error %q), want DEAD after %d", d.Status, d.Attempts, d.LastError, maxAttempts)
+	}
+	if n := worker.Dispatch(context.Background(), now.Add(48*time.Hour)); n != 0 {
+		t.Errorf("dead delivery dispatched again")
+	}
+	if got := len(recv.received()); got != maxAttempts {
+		t.Errorf("receiver got %d requests, want %d", got, maxAttempts)
+	}
+
+	// повторная отправка через API возвращает доставку в очередь
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/webhooks/redeliver", webhook_handler.NewWebhookHandler(repo, discard).Redeliver)
+
+	w := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"delivery_id": ` + strconv.FormatInt(id, 10) + `}`)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks/redeliver", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("redeliver: status %d, body %s", w.Code, w.Body)
+	}
+	if d := getDelivery(t, repo, id); d.Status != models.DeliveryPending || d.Attempts != 0 {
+		t.Fatalf("after redeliver delivery is %s after %d attempts, want PENDING with 0", d.Status, d.Attempts)
+	}
+
+	recv.respond(http.StatusOK)
+	if n := worker.Dispatch(context.Background(), time.Now()); n != 1 {
+		t.Fatalf("dispatched %d deliveries after redeliver, want 1", n)
+	}
+	if d := getDelivery(t, repo, id); d.Status != models.DeliveryDelivered {
+		t.Errorf("delivery is %s, want DELIVERED", d.Status)
+	}
+}